@@ -0,0 +1,94 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// GitHub alert kinds, as written in the [!KIND] marker
+var alertKinds = map[string]bool{
+	"note":      true,
+	"tip":       true,
+	"important": true,
+	"warning":   true,
+	"caution":   true,
+}
+
+// Marker opening a blockquote-based alert
+var alertMarkerPattern = regexp.MustCompile(`^\s*\[!([A-Za-z]+)\]\s*`)
+
+// Helper function to read the alert kind from a div rendered by GitHub,
+// e.g. <div class="markdown-alert markdown-alert-note">
+func divAlertKind(n *html.Node) string {
+	for _, class := range strings.Fields(getAttr(n, "class")) {
+		kind, ok := strings.CutPrefix(class, "markdown-alert-")
+		if ok && alertKinds[kind] {
+			return kind
+		}
+	}
+	return ""
+}
+
+// Helper function to read the [!KIND] marker opening a blockquote
+func blockquoteAlertKind(n *html.Node) string {
+	first := firstElementChild(n)
+	if first == nil || first.Data != "p" || first.FirstChild == nil || first.FirstChild.Type != html.TextNode {
+		return ""
+	}
+
+	match := alertMarkerPattern.FindStringSubmatch(first.FirstChild.Data)
+	if match == nil {
+		return ""
+	}
+	kind := strings.ToLower(match[1])
+	if !alertKinds[kind] {
+		return ""
+	}
+	return kind
+}
+
+// Helper function to drop the [!KIND] marker from the start of an alert body
+func stripAlertMarker(children []Element) []Element {
+	if len(children) == 0 || children[0].Type != "paragraph" {
+		return children
+	}
+	para := &children[0]
+	if len(para.Children) > 0 && para.Children[0].Type == "text" {
+		text := alertMarkerPattern.ReplaceAllString(para.Children[0].Content, "")
+		if text == "" {
+			para.Children = para.Children[1:]
+		} else {
+			para.Children[0].Content = text
+		}
+	}
+	// The marker usually sits on its own line
+	if len(para.Children) > 0 && para.Children[0].Type == "line_break" {
+		para.Children = para.Children[1:]
+	}
+	if len(para.Children) == 0 {
+		return children[1:]
+	}
+	return children
+}
+
+// Helper function to tell apart the title paragraph GitHub puts in a div alert
+func isAlertTitle(n *html.Node) bool {
+	for _, class := range strings.Fields(getAttr(n, "class")) {
+		if class == "markdown-alert-title" {
+			return true
+		}
+	}
+	return false
+}
+
+// Helper function to find the first element child of a node
+func firstElementChild(n *html.Node) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			return c
+		}
+	}
+	return nil
+}
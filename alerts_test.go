@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDivAndBlockquoteAlertsMatch(t *testing.T) {
+	fromBlockquote := findElement(parseTestMarkdown(t, "> [!NOTE]\n> Read the docs first.\n"), "admonition")
+
+	div := `<div class="markdown-alert markdown-alert-note">` +
+		`<p class="markdown-alert-title"><svg class="octicon octicon-info" viewBox="0 0 16 16"><path d="M0 8a8 8 0 1 1 16 0"></path></svg>Note</p>` +
+		`<p>Read the docs first.</p></div>`
+	fromDiv := findElement(parseHTMLToElements(context.Background(), div), "admonition")
+
+	for name, el := range map[string]*Element{"blockquote": fromBlockquote, "div": fromDiv} {
+		if el == nil {
+			t.Errorf("%s alert: no admonition element", name)
+			continue
+		}
+		if el.Attributes.Kind != "note" {
+			t.Errorf("%s alert: kind = %q, want note", name, el.Attributes.Kind)
+		}
+		if got := RenderPlainText(el.Children); got != "Read the docs first.\n" {
+			t.Errorf("%s alert: body = %q, want only the alert text", name, got)
+		}
+	}
+}
+
+func TestUnknownAlertKindIsABlockquote(t *testing.T) {
+	elements := parseTestMarkdown(t, "> [!SHRUG]\n> Not an alert.\n")
+	if findElement(elements, "admonition") != nil || findElement(elements, "blockquote") == nil {
+		t.Errorf("elements = %+v, want a plain blockquote", elements)
+	}
+}
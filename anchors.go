@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// AnchorFix An in-page link rewritten to the ID of the heading it meant
+type AnchorFix struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Point in-page links at real heading IDs when the author's slug is close
+// but not exact, e.g. #Getting_Started for the heading ID getting-started
+func fixAnchorLinks(elements []Element) []AnchorFix {
+	ids := collectAnchors(elements)
+	candidates := make(map[string]map[string]bool)
+	addCandidate := func(key, id string) {
+		if key == "" {
+			return
+		}
+		if candidates[key] == nil {
+			candidates[key] = make(map[string]bool)
+		}
+		candidates[key][id] = true
+	}
+
+	for id, text := range ids {
+		addCandidate(anchorKey(id), id)
+		addCandidate(anchorKey(text), id)
+	}
+
+	var fixes []AnchorFix
+	var rewrite func([]Element)
+	rewrite = func(elements []Element) {
+		for i := range elements {
+			el := &elements[i]
+			if fragment, ok := strings.CutPrefix(el.Attributes.Href, "#"); ok && el.Type == "link" && !hasAnchor(ids, fragment) {
+				// Only rewrite when exactly one heading matches
+				if matches := candidates[anchorKey(fragment)]; len(matches) == 1 {
+					for id := range matches {
+						fixes = append(fixes, AnchorFix{From: el.Attributes.Href, To: "#" + id})
+						el.Attributes.Href = "#" + id
+					}
+				}
+			}
+			rewrite(el.Children)
+		}
+	}
+	rewrite(elements)
+
+	return fixes
+}
+
+// Helper function to reduce an anchor or heading text to its letters and
+// digits, so case, spacing and punctuation differences still match
+func anchorKey(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// Map every heading ID to its heading text
+func collectAnchors(elements []Element) map[string]string {
+	anchors := make(map[string]string)
+	var collect func([]Element)
+	collect = func(elements []Element) {
+		for _, el := range elements {
+			if el.Type == "heading" && el.Attributes.ID != "" {
+				anchors[el.Attributes.ID] = el.Content
+			}
+			collect(el.Children)
+		}
+	}
+	collect(elements)
+	return anchors
+}
+
+// Helper function to check whether a heading ID exists
+func hasAnchor(anchors map[string]string, id string) bool {
+	_, ok := anchors[id]
+	return ok
+}
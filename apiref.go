@@ -0,0 +1,34 @@
+package main
+
+// Group each heading with the code blocks in its section into api_entry
+// elements, for READMEs that document one function per heading
+func buildAPIReference(elements []Element) []Element {
+	var entries []Element
+	for _, section := range splitSections(elements) {
+		if section.heading == "" {
+			// Content before the first heading documents nothing by name
+			continue
+		}
+
+		var examples []Element
+		for _, el := range section.elements {
+			if el.Type == "code_block" {
+				examples = append(examples, el)
+			}
+		}
+		if len(examples) == 0 {
+			continue
+		}
+
+		entries = append(entries, Element{
+			Type:    "api_entry",
+			Content: section.heading,
+			Attributes: Attributes{
+				ID:        section.id,
+				Signature: firstLine(examples[0].Content),
+			},
+			Children: examples,
+		})
+	}
+	return entries
+}
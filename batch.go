@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Default concurrency limits for batch processing
+const (
+	defaultBatchConcurrency       = 5
+	defaultBatchGlobalConcurrency = 20
+	maxBatchPayloadBytes          = 1 << 20
+	maxBatchItems                 = 20
+)
+
+// BatchItem Repository requested in a batch
+type BatchItem struct {
+	Owner string `json:"owner"`
+	Repo  string `json:"repo"`
+	Ref   string `json:"ref,omitempty"`
+}
+
+// BatchResult Outcome of one batch item
+type BatchResult struct {
+	Owner    string            `json:"owner"`
+	Repo     string            `json:"repo"`
+	Ref      string            `json:"ref,omitempty"`
+	Status   string            `json:"status"`
+	Error    string            `json:"error,omitempty"`
+	Document *MarkdownDocument `json:"document,omitempty"`
+}
+
+// Per-request limit, configured from BATCH_CONCURRENCY at startup
+var batchConcurrency = defaultBatchConcurrency
+
+// Limit shared by all batch requests, configured from BATCH_GLOBAL_CONCURRENCY at startup
+var batchSemaphore = make(chan struct{}, defaultBatchGlobalConcurrency)
+
+// Configure batch concurrency limits from the environment
+func configureBatchConcurrency() error {
+	perRequest, err := positiveIntFromEnv("BATCH_CONCURRENCY", defaultBatchConcurrency)
+	if err != nil {
+		return err
+	}
+	global, err := positiveIntFromEnv("BATCH_GLOBAL_CONCURRENCY", defaultBatchGlobalConcurrency)
+	if err != nil {
+		return err
+	}
+
+	batchConcurrency = perRequest
+	batchSemaphore = make(chan struct{}, global)
+	return nil
+}
+
+// Helper function to read a positive integer from the environment
+func positiveIntFromEnv(name string, fallback int) (int, error) {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 1 {
+		return 0, fmt.Errorf("invalid %s %q", name, raw)
+	}
+	return value, nil
+}
+
+// HTTP Handler processing several READMEs in one call
+func handleBatchRequest(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	setCORSHeaders(w, r)
+
+	// Handle preflight requests
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSONError(w, http.StatusMethodNotAllowed, errorCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var items []BatchItem
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxBatchPayloadBytes)).Decode(&items); err != nil {
+		writeJSONError(w, http.StatusBadRequest, errorCodeBadRequest, "Request body must be a JSON array of {owner, repo, ref} objects")
+		return
+	}
+	if len(items) > maxBatchItems {
+		writeJSONError(w, http.StatusBadRequest, errorCodeBadRequest, fmt.Sprintf("A batch can contain at most %d repositories", maxBatchItems))
+		return
+	}
+
+	opts, err := parseReadmeOptions(r.URL.Query())
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, errorCodeBadRequest, err.Error())
+		return
+	}
+
+	// Results are always a JSON array, so other renderings cannot be honoured
+	if opts.Format != formatJSON {
+		writeJSONError(w, http.StatusBadRequest, errorCodeBadRequest, fmt.Sprintf("format=%s is not supported for batch requests", opts.Format))
+		return
+	}
+	if r.URL.Query().Get("envelope") == "true" {
+		writeJSONError(w, http.StatusBadRequest, errorCodeBadRequest, "envelope is not supported for batch requests")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(withRequestToken(r.Context(), r), 60*time.Second)
+	defer cancel()
+
+	// Items beyond the limits queue until a slot frees up
+	perRequest := make(chan struct{}, batchConcurrency)
+	results := make([]BatchResult, len(items))
+
+	var wg sync.WaitGroup
+	for i, item := range items {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = processBatchItem(ctx, item, opts, perRequest)
+		}()
+	}
+	wg.Wait()
+
+	// Remap JSON keys and element types like single documents
+	var response any = results
+	if opts.Casing != "" {
+		response, err = applyCasing(results, opts.Casing)
+		if err != nil {
+			logf(ctx, "Error applying casing: %v", err)
+			writeJSONError(w, http.StatusInternalServerError, errorCodeInternal, "Failed to encode response")
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logf(ctx, "Error encoding response: %v", err)
+	}
+}
+
+// Process one batch item within the per-request and global limits
+func processBatchItem(ctx context.Context, item BatchItem, opts readmeOptions, perRequest chan struct{}) BatchResult {
+	result := BatchResult{Owner: item.Owner, Repo: item.Repo, Ref: item.Ref}
+
+	if item.Owner == "" || item.Repo == "" {
+		result.Status = "error"
+		result.Error = "Owner and repository are required"
+		return result
+	}
+	if err := validateRepository(item.Owner, item.Repo); err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+
+	// A per-item ref overrides the query's ref
+	if item.Ref != "" {
+		if opts.Release != "" || opts.CompareHead != "" {
+			result.Status = "error"
+			result.Error = "ref cannot be combined with release or compare"
+			return result
+		}
+		opts.Ref = item.Ref
+	}
+
+	release, err := acquireSlots(ctx, perRequest, batchSemaphore)
+	if err != nil {
+		result.Status = "error"
+		result.Error = "Batch timed out before this item was processed"
+		return result
+	}
+	defer release()
+
+	doc, err := processReadme(ctx, item.Owner, item.Repo, opts)
+	if err != nil {
+		logf(ctx, "Error processing README for %s/%s: %v", item.Owner, item.Repo, err)
+		_, message := upstreamErrorStatus(err, "README")
+		result.Status = "error"
+		result.Error = message
+		return result
+	}
+
+	doc = applyDocumentOptions(doc, item.Owner, item.Repo, opts)
+
+	result.Status = "ok"
+	result.Document = &doc
+	return result
+}
+
+// Helper function to take a slot from each semaphore in order
+func acquireSlots(ctx context.Context, semaphores ...chan struct{}) (func(), error) {
+	var held []chan struct{}
+	release := func() {
+		for _, sem := range held {
+			<-sem
+		}
+	}
+
+	for _, sem := range semaphores {
+		select {
+		case sem <- struct{}{}:
+			held = append(held, sem)
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Helper function to send a batch request and decode the results
+func postBatch(t *testing.T, query, body string) (*httptest.ResponseRecorder, []BatchResult) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/readme/batch?"+query, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handleBatchRequest(rec, req)
+
+	var results []BatchResult
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+			t.Fatalf("decoding results: %v\n%s", err, rec.Body.String())
+		}
+	}
+	return rec, results
+}
+
+func TestBatchMixesSuccessesAndFailures(t *testing.T) {
+	stubReadmes(t, map[string]string{
+		"octo/one": "# One",
+		"octo/two": "# Two",
+	})
+
+	rec, results := postBatch(t, "", `[{"owner":"octo","repo":"one"},{"owner":"octo","repo":"missing"},{"owner":"octo","repo":"two"},{"owner":"","repo":"x"}]`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want 4", len(results))
+	}
+
+	for i, want := range []struct{ repo, status string }{{"one", "ok"}, {"missing", "error"}, {"two", "ok"}, {"x", "error"}} {
+		if results[i].Repo != want.repo || results[i].Status != want.status {
+			t.Errorf("result %d = %s/%s, want %s/%s", i, results[i].Repo, results[i].Status, want.repo, want.status)
+		}
+	}
+	if heading := findElement(results[0].Document.Content, "heading"); heading == nil || heading.Content != "One" {
+		t.Errorf("first document = %+v", results[0].Document)
+	}
+	if results[1].Document != nil || results[1].Error == "" {
+		t.Errorf("missing repository result = %+v", results[1])
+	}
+}
+
+func TestBatchAppliesDocumentOptions(t *testing.T) {
+	stubReadmes(t, map[string]string{"octo/one": "# Install\n\nRun it\n\n## Usage\n\nUse it"})
+
+	rec, results := postBatch(t, "anchors=true&limit=1", `[{"owner":"octo","repo":"one"}]`)
+	if rec.Code != http.StatusOK || len(results) != 1 || results[0].Document == nil {
+		t.Fatalf("status = %d, results %+v", rec.Code, results)
+	}
+
+	doc := results[0].Document
+	if len(doc.TableOfContents) == 0 {
+		t.Error("batch document has no table of contents")
+	}
+	if _, ok := doc.Anchors["usage"]; !ok {
+		t.Errorf("anchors = %v, want usage", doc.Anchors)
+	}
+	if len(doc.Content) != 1 {
+		t.Errorf("limit=1 returned %d elements", len(doc.Content))
+	}
+}
+
+func TestBatchRejectsUnsupportedOptions(t *testing.T) {
+	stubReadmes(t, map[string]string{"octo/one": "# One"})
+
+	for _, query := range []string{"format=html", "format=markdown", "canonical=true", "envelope=true"} {
+		rec, _ := postBatch(t, query, `[{"owner":"octo","repo":"one"}]`)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+func TestBatchSizeIsCapped(t *testing.T) {
+	items := make([]string, maxBatchItems+1)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"owner":"octo","repo":"r%d"}`, i)
+	}
+	rec, _ := postBatch(t, "", "["+strings.Join(items, ",")+"]")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestBatchConcurrencyIsBounded(t *testing.T) {
+	previous := batchConcurrency
+	batchConcurrency = 2
+	t.Cleanup(func() { batchConcurrency = previous })
+
+	var mu sync.Mutex
+	active, peak := 0, 0
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/readme") {
+			fmt.Fprint(w, `{"name":"r"}`)
+			return
+		}
+		mu.Lock()
+		active++
+		peak = max(peak, active)
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+		fmt.Fprint(w, `{"content":"IyBS"}`)
+	})
+
+	items := make([]string, 6)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"owner":"octo","repo":"r%d"}`, i)
+	}
+	rec, results := postBatch(t, "", "["+strings.Join(items, ",")+"]")
+	if rec.Code != http.StatusOK || len(results) != 6 {
+		t.Fatalf("status = %d, %d results", rec.Code, len(results))
+	}
+	if peak > 2 {
+		t.Errorf("%d README fetches ran at once, want at most 2", peak)
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default lifetime of cached documents
+const defaultCacheTTL = 5 * time.Minute
+
+// Default number of documents kept per cache
+const defaultCacheMaxEntries = 1000
+
+// In-memory cache of processed documents, bounded by maxEntries
+type documentCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	doc      MarkdownDocument
+	expires  time.Time
+	lastUsed time.Time
+}
+
+// Cache keying modes
+const (
+	// Cache whole documents per repository, skipping GitHub on a hit
+	cacheModeRepository = "repository"
+	// Cache parsed content by content hash, deduplicating identical READMEs
+	cacheModeContent = "content"
+)
+
+// Shared cache for processed READMEs, configured from CACHE_TTL at startup
+var readmeCache = newDocumentCache(defaultCacheTTL, defaultCacheMaxEntries)
+
+// Parsed content keyed by content hash, used in content mode
+var contentCache = newDocumentCache(defaultCacheTTL, defaultCacheMaxEntries)
+
+// Active cache mode, configured from CACHE_MODE at startup
+var cacheMode = cacheModeRepository
+
+// Read the cache mode from the environment
+func cacheModeFromEnv() (string, error) {
+	switch mode := os.Getenv("CACHE_MODE"); mode {
+	case "":
+		return cacheModeRepository, nil
+	case cacheModeRepository, cacheModeContent:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid CACHE_MODE %q, expected %q or %q", mode, cacheModeRepository, cacheModeContent)
+	}
+}
+
+func newDocumentCache(ttl time.Duration, maxEntries int) *documentCache {
+	return &documentCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*cacheEntry),
+	}
+}
+
+// Read the cache TTL from the environment
+func cacheTTLFromEnv() (time.Duration, error) {
+	raw := os.Getenv("CACHE_TTL")
+	if raw == "" {
+		return defaultCacheTTL, nil
+	}
+
+	ttl, err := time.ParseDuration(raw)
+	if err != nil || ttl < 0 {
+		return 0, fmt.Errorf("invalid CACHE_TTL %q", raw)
+	}
+	return ttl, nil
+}
+
+// Read the cache size bound from the environment
+func cacheMaxEntriesFromEnv() (int, error) {
+	return positiveIntFromEnv("CACHE_MAX_ENTRIES", defaultCacheMaxEntries)
+}
+
+// Cache key for a repository document rendered with the given options.
+// Repository names are case-insensitive on GitHub.
+func readmeCacheKey(owner, repo string, opts readmeOptions) string {
+	return fmt.Sprintf("%s|%s|ref=%s|release=%s|%s",
+		repositoryCacheKey(owner, repo), opts.Path, opts.Ref, opts.Release, parseOptionsKey(opts))
+}
+
+// Cache key for content with the given hash parsed with the given options
+func contentCacheKey(contentHash string, opts readmeOptions) string {
+	return contentHash + "|" + parseOptionsKey(opts)
+}
+
+// Helper function to build the part of a cache key for options that change parsing
+func parseOptionsKey(opts readmeOptions) string {
+	return fmt.Sprintf("html=%t|sanitize=%t|sourcemd=%t|positions=%t|hardbreaks=%t|tables=%t|footnotes=%t",
+		opts.Format == formatHTML, opts.Sanitize, opts.SourceMarkdown, opts.Positions,
+		opts.Markdown.HardLineBreaks, opts.Markdown.Tables, opts.Markdown.Footnotes)
+}
+
+// Helper function to build the repository part of a cache key
+func repositoryCacheKey(owner, repo string) string {
+	return strings.ToLower(owner + "/" + repo)
+}
+
+// Get a copy of a cached document that has not expired
+func (c *documentCache) get(key string) (MarkdownDocument, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	now := time.Now()
+	if !ok || now.After(entry.expires) {
+		return MarkdownDocument{}, false
+	}
+	entry.lastUsed = now
+
+	// Handlers modify documents in place, so never hand out the cached tree
+	doc := entry.doc
+	doc.Content = cloneElements(entry.doc.Content)
+	doc.Footnotes = cloneElements(entry.doc.Footnotes)
+	return doc, true
+}
+
+// Store a copy of a document
+func (c *documentCache) set(key string, doc MarkdownDocument) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	doc.Content = cloneElements(doc.Content)
+	doc.Footnotes = cloneElements(doc.Footnotes)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		c.evict(now)
+	}
+	c.entries[key] = &cacheEntry{doc: doc, expires: now.Add(c.ttl), lastUsed: now}
+}
+
+// Make room for one entry: drop expired entries, or failing that the least
+// recently used one. Callers hold the lock.
+func (c *documentCache) evict(now time.Time) {
+	for key, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+	if len(c.entries) < c.maxEntries {
+		return
+	}
+
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if oldestKey == "" || entry.lastUsed.Before(oldest) {
+			oldestKey, oldest = key, entry.lastUsed
+		}
+	}
+	delete(c.entries, oldestKey)
+}
+
+// Drop every cached document belonging to a repository
+func (c *documentCache) invalidateRepository(owner, repo string) int {
+	prefix := repositoryCacheKey(owner, repo) + "|"
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Helper function to deep copy an element tree
+func cloneElements(elements []Element) []Element {
+	if elements == nil {
+		return nil
+	}
+
+	cloned := make([]Element, len(elements))
+	for i, el := range elements {
+		cloned[i] = el
+		cloned[i].Children = cloneElements(el.Children)
+		if el.Attributes.HighlightLines != nil {
+			cloned[i].Attributes.HighlightLines = append([]int(nil), el.Attributes.HighlightLines...)
+		}
+		if el.Attributes.Imports != nil {
+			cloned[i].Attributes.Imports = append([]string(nil), el.Attributes.Imports...)
+		}
+		cloned[i].Attributes.Data = maps.Clone(el.Attributes.Data)
+		if el.Attributes.Checked != nil {
+			checked := *el.Attributes.Checked
+			cloned[i].Attributes.Checked = &checked
+		}
+	}
+	return cloned
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCachedReadmeMakesNoGitHubCalls(t *testing.T) {
+	calls := 0
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if strings.HasSuffix(r.URL.Path, "/readme") {
+			w.Write([]byte(`{"content":"IyBEZW1v"}`))
+			return
+		}
+		w.Write([]byte(`{"name":"demo"}`))
+	})
+
+	if rec := getReadme(t, "owner=octo&repo=demo", nil); rec.Code != http.StatusOK {
+		t.Fatalf("first request: status %d", rec.Code)
+	}
+	first := calls
+	if first == 0 {
+		t.Fatal("first request made no GitHub calls")
+	}
+
+	// Repository names are case-insensitive
+	if rec := getReadme(t, "owner=Octo&repo=Demo", nil); rec.Code != http.StatusOK {
+		t.Fatalf("second request: status %d", rec.Code)
+	}
+	if calls != first {
+		t.Errorf("cache hit made %d GitHub calls, want none", calls-first)
+	}
+
+	// A different ref is a different document
+	getReadme(t, "owner=octo&repo=demo&ref=develop", nil)
+	if calls == first {
+		t.Error("request for another ref was served from the cache")
+	}
+}
+
+func TestCacheExpiresAndEvicts(t *testing.T) {
+	expiring := newDocumentCache(time.Millisecond, 10)
+	expiring.set("a", MarkdownDocument{RawContent: "a"})
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := expiring.get("a"); ok {
+		t.Error("expired entry was returned")
+	}
+
+	bounded := newDocumentCache(time.Minute, 2)
+	bounded.set("a", MarkdownDocument{RawContent: "a"})
+	time.Sleep(time.Millisecond)
+	bounded.set("b", MarkdownDocument{RawContent: "b"})
+	time.Sleep(time.Millisecond)
+	bounded.get("a")
+	bounded.set("c", MarkdownDocument{RawContent: "c"})
+
+	if _, ok := bounded.get("b"); ok {
+		t.Error("least recently used entry was kept")
+	}
+	for _, key := range []string{"a", "c"} {
+		if doc, ok := bounded.get(key); !ok || doc.RawContent != key {
+			t.Errorf("entry %q = %+v, %v", key, doc, ok)
+		}
+	}
+}
+
+func TestCachedDocumentsAreCopies(t *testing.T) {
+	cache := newDocumentCache(time.Minute, 10)
+	cache.set("a", MarkdownDocument{Content: []Element{{Type: "paragraph", Content: "kept"}}})
+
+	doc, _ := cache.get("a")
+	doc.Content[0].Content = "changed"
+	if again, _ := cache.get("a"); again.Content[0].Content != "kept" {
+		t.Errorf("cached document was modified through a returned copy: %+v", again.Content)
+	}
+}
+
+func TestCachedAttributesAreCopies(t *testing.T) {
+	checked := true
+	cache := newDocumentCache(time.Minute, 10)
+	cache.set("a", MarkdownDocument{Content: []Element{{
+		Type: "list_item",
+		Attributes: Attributes{
+			Imports: []string{"fmt"},
+			Data:    map[string]string{"role": "kept"},
+			Checked: &checked,
+		},
+	}}})
+
+	doc, _ := cache.get("a")
+	attrs := &doc.Content[0].Attributes
+	attrs.Imports[0] = "changed"
+	attrs.Data["role"] = "changed"
+	*attrs.Checked = false
+
+	again, _ := cache.get("a")
+	kept := again.Content[0].Attributes
+	if kept.Imports[0] != "fmt" || kept.Data["role"] != "kept" || !*kept.Checked {
+		t.Errorf("cached attributes were modified through a returned copy: %+v", kept)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Supported JSON casing conventions
+const (
+	casingSnake = "snake"
+	casingCamel = "camel"
+)
+
+// Keys whose values are passed through untouched, either a bare key or
+// "parent.key". Heading IDs and data-* names are user data, not schema.
+var opaqueCasingKeys = map[string]bool{
+	"githubResponses": true,
+	"anchors":         true,
+	"attributes.data": true,
+}
+
+// Remap JSON keys and element type values of v to the requested casing
+func applyCasing(v any, casing string) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encoding for casing: %w", err)
+	}
+
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, fmt.Errorf("decoding for casing: %w", err)
+	}
+
+	convert := toSnakeCase
+	if casing == casingCamel {
+		convert = toCamelCase
+	}
+
+	return recase(generic, "", convert), nil
+}
+
+// Helper function to walk decoded JSON renaming keys and "type" values.
+// parent is the key holding v, checked against opaqueCasingKeys.
+func recase(v any, parent string, convert func(string) string) any {
+	switch value := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(value))
+		for key, child := range value {
+			switch {
+			case opaqueCasingKeys[key] || opaqueCasingKeys[parent+"."+key]:
+				out[convert(key)] = child
+			case key == "type":
+				if s, ok := child.(string); ok {
+					child = convert(s)
+				}
+				out[key] = child
+			default:
+				out[convert(key)] = recase(child, key, convert)
+			}
+		}
+		return out
+
+	case []any:
+		for i := range value {
+			value[i] = recase(value[i], parent, convert)
+		}
+		return value
+	}
+
+	return v
+}
+
+// Helper function to turn "rawContent" into "raw_content"
+func toSnakeCase(s string) string {
+	var sb strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// Helper function to turn "unordered_list" into "unorderedList"
+func toCamelCase(s string) string {
+	parts := strings.Split(s, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
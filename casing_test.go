@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// Helper function to recase a value and decode it back into a generic map
+func recasedJSON(t *testing.T, v any, casing string) map[string]any {
+	t.Helper()
+	recased, err := applyCasing(v, casing)
+	if err != nil {
+		t.Fatalf("applyCasing: %v", err)
+	}
+	data, err := json.Marshal(recased)
+	if err != nil {
+		t.Fatalf("encoding: %v", err)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("decoding: %v", err)
+	}
+	return out
+}
+
+func TestCasingRemapsKeysAndTypes(t *testing.T) {
+	doc := MarkdownDocument{
+		RawContent: "# Hi",
+		Content:    []Element{{Type: "unordered_list"}},
+	}
+
+	snake := recasedJSON(t, doc, casingSnake)
+	if _, ok := snake["raw_content"]; !ok {
+		t.Errorf("snake keys = %v, want raw_content", snake)
+	}
+
+	camel := recasedJSON(t, doc, casingCamel)
+	content := camel["content"].([]any)
+	if got := content[0].(map[string]any)["type"]; got != "unorderedList" {
+		t.Errorf("camel type = %v, want unorderedList", got)
+	}
+	if _, ok := camel["rawContent"]; !ok {
+		t.Errorf("camel keys = %v, want rawContent", camel)
+	}
+}
+
+func TestCasingLeavesUserDataKeys(t *testing.T) {
+	doc := MarkdownDocument{
+		Anchors: map[string]string{"getting_started": "Getting started", "apiReference": "API"},
+		Content: []Element{{
+			Type:       "image",
+			Attributes: Attributes{Data: map[string]string{"chart_type": "bar", "maxValue": "10"}},
+		}},
+	}
+
+	for _, casing := range []string{casingSnake, casingCamel} {
+		out := recasedJSON(t, ResponseEnvelope{Data: doc}, casing)
+		data := out["data"].(map[string]any)
+
+		anchors := data["anchors"].(map[string]any)
+		if _, ok := anchors["getting_started"]; !ok {
+			t.Errorf("%s: anchor keys renamed: %v", casing, anchors)
+		}
+		if _, ok := anchors["apiReference"]; !ok {
+			t.Errorf("%s: anchor keys renamed: %v", casing, anchors)
+		}
+
+		attrs := data["content"].([]any)[0].(map[string]any)["attributes"].(map[string]any)
+		dataAttrs := attrs["data"].(map[string]any)
+		if dataAttrs["chart_type"] != "bar" || dataAttrs["maxValue"] != "10" {
+			t.Errorf("%s: data-* keys renamed: %v", casing, dataAttrs)
+		}
+	}
+}
+
+func TestCasingStillRecasesEnvelopeData(t *testing.T) {
+	out := recasedJSON(t, ResponseEnvelope{Data: MarkdownDocument{RawContent: "x"}, Meta: ResponseMeta{SchemaVersion: "1"}}, casingSnake)
+	if _, ok := out["data"].(map[string]any)["raw_content"]; !ok {
+		t.Errorf("envelope data was not recased: %v", out["data"])
+	}
+	if _, ok := out["meta"].(map[string]any)["schema_version"]; !ok {
+		t.Errorf("envelope meta was not recased: %v", out["meta"])
+	}
+}
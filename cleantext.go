@@ -0,0 +1,57 @@
+package main
+
+import "strings"
+
+// Invisible characters that break search and copy-paste
+var invisibleCharReplacer = strings.NewReplacer(
+	"\u200B", "", // zero-width space
+	"\u200C", "", // zero-width non-joiner
+	"\u200D", "", // zero-width joiner
+	"\u2060", "", // word joiner
+	"\uFEFF", "", // zero-width no-break space
+	"\u00AD", "", // soft hyphen
+)
+
+// Strip zero-width characters and soft hyphens from element text. Code
+// is left as written.
+func applyCleanText(elements []Element) {
+	for i := range elements {
+		el := &elements[i]
+		if el.Type != "code" && el.Type != "code_block" {
+			el.Content = replaceOutsideCode(*el, invisibleCharReplacer.Replace)
+		}
+		applyCleanText(el.Children)
+	}
+}
+
+// Helper function to rewrite the Content of an element. Containers such as
+// headings repeat the text of their children in Content, so the text of
+// inline code children is kept as written.
+func replaceOutsideCode(el Element, replace func(string) string) string {
+	var codes []string
+	var collect func([]Element)
+	collect = func(elements []Element) {
+		for _, child := range elements {
+			if child.Type == "code" || child.Type == "code_block" {
+				codes = append(codes, child.Content)
+				continue
+			}
+			collect(child.Children)
+		}
+	}
+	collect(el.Children)
+
+	var sb strings.Builder
+	rest := el.Content
+	for _, code := range codes {
+		i := strings.Index(rest, code)
+		if code == "" || i < 0 {
+			continue
+		}
+		sb.WriteString(replace(rest[:i]))
+		sb.WriteString(code)
+		rest = rest[i+len(code):]
+	}
+	sb.WriteString(replace(rest))
+	return sb.String()
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCleanTextStripsInvisibleCharacters(t *testing.T) {
+	source := "Zero\u200Bwidth and BOM\uFEFF and hy\u00ADphen\n\n`code\u200B`\n"
+
+	raw := parseTestMarkdownDoc(t, source, "")
+	if got := RenderPlainText(raw.Content); !strings.Contains(got, "\u200B") {
+		t.Errorf("text changed without cleantext: %q", got)
+	}
+
+	doc := applyDocumentOptions(parseTestMarkdownDoc(t, source, "cleantext=true"), "octo", "demo", testOptions(t, "cleantext=true"))
+	paragraphs := findElements(doc.Content, "paragraph")
+	if len(paragraphs) != 2 {
+		t.Fatalf("paragraphs = %+v", paragraphs)
+	}
+	if got := RenderPlainText(paragraphs[:1]); got != "Zerowidth and BOM and hyphen\n" {
+		t.Errorf("cleaned text = %q", got)
+	}
+	if code := findElement(doc.Content, "code"); code == nil || code.Content != "code\u200B" {
+		t.Errorf("code was cleaned: %+v", code)
+	}
+	if doc.RawContent != source {
+		t.Errorf("raw content changed: %q", doc.RawContent)
+	}
+}
+
+func TestCleanTextKeepsInlineCodeInHeadings(t *testing.T) {
+	source := "## Zero\u200Bwidth `code\u200B` here\n"
+	doc := applyDocumentOptions(parseTestMarkdownDoc(t, source, "cleantext=true"), "octo", "demo", testOptions(t, "cleantext=true"))
+
+	heading := findElement(doc.Content, "heading")
+	if heading == nil {
+		t.Fatal("no heading element")
+	}
+	if heading.Content != "Zerowidth code\u200B here" {
+		t.Errorf("heading = %+v, want only the text outside code cleaned", heading)
+	}
+	if code := findElement(heading.Children, "code"); code == nil || code.Content != "code\u200B" {
+		t.Errorf("code = %+v, want it verbatim", code)
+	}
+}
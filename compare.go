@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// SectionChange A top-level section that differs between two refs
+type SectionChange struct {
+	ID      string `json:"id"`
+	Heading string `json:"heading"`
+	Status  string `json:"status"`
+}
+
+// Section change statuses
+const (
+	sectionAdded   = "added"
+	sectionRemoved = "removed"
+	sectionChanged = "changed"
+)
+
+// A heading and the elements that follow it up to the next heading
+type documentSection struct {
+	id       string
+	heading  string
+	elements []Element
+}
+
+// Helper function to copy elements without their source positions, which
+// change whenever earlier content grows or shrinks
+func withoutPositions(elements []Element) []Element {
+	stripped := cloneElements(elements)
+	var strip func([]Element)
+	strip = func(elements []Element) {
+		for i := range elements {
+			elements[i].Line = 0
+			elements[i].SourceMarkdown = ""
+			strip(elements[i].Children)
+		}
+	}
+	strip(stripped)
+	return stripped
+}
+
+// Parse a "base..head" comparison range
+func parseCompareRange(raw string) (string, string, error) {
+	base, head, ok := strings.Cut(raw, "..")
+	if !ok || base == "" || head == "" || strings.Contains(head, "..") {
+		return "", "", fmt.Errorf("compare must look like base..head")
+	}
+	return base, head, nil
+}
+
+// Fetch the README at two refs and keep only the sections that changed
+func compareReadme(ctx context.Context, owner, repo string, opts readmeOptions) (MarkdownDocument, error) {
+	g, gctx := errgroup.WithContext(ctx)
+
+	var baseContent, headContent string
+	g.Go(func() error {
+		var err error
+		baseContent, err = getReadmeContent(gctx, owner, repo, opts.CompareBase)
+		if err != nil {
+			return fmt.Errorf("fetching readme at %s: %w", opts.CompareBase, err)
+		}
+		return nil
+	})
+	g.Go(func() error {
+		var err error
+		headContent, err = getReadmeContent(gctx, owner, repo, opts.CompareHead)
+		if err != nil {
+			return fmt.Errorf("fetching readme at %s: %w", opts.CompareHead, err)
+		}
+		return nil
+	})
+
+	var metadata DocumentMetadata
+	g.Go(func() error {
+		var err error
+		metadata, err = getRepositoryMetadata(gctx, owner, repo)
+		if err != nil {
+			return fmt.Errorf("fetching metadata: %w", err)
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return MarkdownDocument{}, err
+	}
+
+	base := renderDocument(ctx, baseContent, metadata, opts)
+	doc := renderDocument(ctx, headContent, metadata, opts)
+
+	doc.Metadata.Ref = opts.CompareHead
+	doc.Content, doc.SectionChanges = diffSections(splitSections(base.Content), splitSections(doc.Content))
+	return doc, nil
+}
+
+// Split top-level elements into sections starting at each heading
+func splitSections(elements []Element) []documentSection {
+	var sections []documentSection
+	current := documentSection{}
+
+	for _, el := range elements {
+		if el.Type == "heading" {
+			if len(current.elements) > 0 {
+				sections = append(sections, current)
+			}
+			current = documentSection{id: sectionID(el), heading: el.Content}
+		}
+		current.elements = append(current.elements, el)
+	}
+	if len(current.elements) > 0 {
+		sections = append(sections, current)
+	}
+
+	return sections
+}
+
+// Helper function to identify a section by heading ID, falling back to its text
+func sectionID(heading Element) string {
+	if heading.Attributes.ID != "" {
+		return heading.Attributes.ID
+	}
+	return strings.ToLower(heading.Content)
+}
+
+// Compare sections by ID, returning the changed head sections and a summary
+func diffSections(base, head []documentSection) ([]Element, []SectionChange) {
+	baseByID := make(map[string]documentSection, len(base))
+	for _, section := range base {
+		baseByID[section.id] = section
+	}
+
+	var content []Element
+	var changes []SectionChange
+	seen := make(map[string]bool, len(head))
+
+	for _, section := range head {
+		seen[section.id] = true
+
+		previous, existed := baseByID[section.id]
+		switch {
+		case !existed:
+			changes = append(changes, SectionChange{ID: section.id, Heading: section.heading, Status: sectionAdded})
+		case !reflect.DeepEqual(withoutPositions(previous.elements), withoutPositions(section.elements)):
+			changes = append(changes, SectionChange{ID: section.id, Heading: section.heading, Status: sectionChanged})
+		default:
+			continue
+		}
+		content = append(content, section.elements...)
+	}
+
+	for _, section := range base {
+		if !seen[section.id] {
+			changes = append(changes, SectionChange{ID: section.id, Heading: section.heading, Status: sectionRemoved})
+		}
+	}
+
+	return content, changes
+}
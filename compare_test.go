@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Helper function to serve a README per ref
+func stubReadmeRefs(t *testing.T, readmes map[string]string) {
+	t.Helper()
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/octo/demo/readme" {
+			fmt.Fprint(w, `{"name":"demo","default_branch":"main"}`)
+			return
+		}
+		content, ok := readmes[r.URL.Query().Get("ref")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"content":%q}`, base64.StdEncoding.EncodeToString([]byte(content)))
+	})
+}
+
+func TestShiftedSectionIsUnchanged(t *testing.T) {
+	stubReadmeRefs(t, map[string]string{
+		"v1": "# Intro\n\nShort.\n\n## Usage\n\nRun it.\n",
+		"v2": "# Intro\n\nShort.\n\nNow with\nseveral more\nlines.\n\n## Usage\n\nRun it.\n",
+	})
+
+	opts := testOptions(t, "compare=v1..v2&positions=true&sourcemd=true")
+	doc, err := compareReadme(context.Background(), "octo", "demo", opts)
+	if err != nil {
+		t.Fatalf("compareReadme: %v", err)
+	}
+	if len(doc.SectionChanges) != 1 || doc.SectionChanges[0].ID != "intro" || doc.SectionChanges[0].Status != sectionChanged {
+		t.Errorf("changes = %+v, want only intro changed", doc.SectionChanges)
+	}
+}
+
+func TestCompareFetchesConcurrently(t *testing.T) {
+	const delay = 200 * time.Millisecond
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		if r.URL.Path == "/repos/octo/demo/readme" {
+			fmt.Fprintf(w, `{"content":%q}`, base64.StdEncoding.EncodeToString([]byte("# Demo\n")))
+			return
+		}
+		fmt.Fprint(w, `{"name":"demo"}`)
+	})
+
+	start := time.Now()
+	if _, err := compareReadme(context.Background(), "octo", "demo", testOptions(t, "compare=v1..v2")); err != nil {
+		t.Fatalf("compareReadme: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 2*delay {
+		t.Errorf("comparison took %v, want close to %v rather than %v", elapsed, delay, 3*delay)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Origins allowed to call the API, configured from ALLOWED_ORIGINS at
+// startup. Empty allows every origin.
+var allowedOrigins []string
+
+// Methods advertised to browsers, configured from ALLOWED_METHODS at startup
+var allowedMethods = "GET"
+
+// Read the CORS configuration from the environment
+func corsConfigFromEnv() ([]string, string) {
+	var origins []string
+	for _, origin := range strings.Split(os.Getenv("ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, strings.TrimRight(origin, "/"))
+		}
+	}
+
+	methods := "GET"
+	if raw := strings.TrimSpace(os.Getenv("ALLOWED_METHODS")); raw != "" {
+		methods = raw
+	}
+	return origins, methods
+}
+
+// Set CORS headers, echoing the request's Origin only when it is allowed.
+// Handlers that validate before serveDocument call this twice, so Vary is
+// only added once.
+func setCORSHeaders(w http.ResponseWriter, r *http.Request) {
+	if !slices.Contains(w.Header().Values("Vary"), "Origin") {
+		w.Header().Add("Vary", "Origin")
+	}
+	if len(allowedOrigins) == 0 {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		origin := r.Header.Get("Origin")
+		if !originAllowed(origin) {
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+	w.Header().Set("Access-Control-Allow-Methods", allowedMethods)
+	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-GitHub-Token, X-Request-ID")
+	w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
+}
+
+// Helper function to check an origin against the allow-list
+func originAllowed(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	for _, allowed := range allowedOrigins {
+		if strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
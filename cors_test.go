@@ -0,0 +1,106 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Helper function to set the CORS configuration for one test
+func useCORSConfig(t *testing.T, origins []string, methods string) {
+	t.Helper()
+	previousOrigins, previousMethods := allowedOrigins, allowedMethods
+	allowedOrigins, allowedMethods = origins, methods
+	t.Cleanup(func() { allowedOrigins, allowedMethods = previousOrigins, previousMethods })
+}
+
+// Helper function to get the CORS headers sent for a request Origin
+func corsHeaders(origin string) http.Header {
+	req := httptest.NewRequest(http.MethodGet, "/readme", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	rec := httptest.NewRecorder()
+	setCORSHeaders(rec, req)
+	return rec.Header()
+}
+
+func TestCORSAllowList(t *testing.T) {
+	t.Setenv("ALLOWED_ORIGINS", "https://docs.example.com/, https://app.example.com")
+	t.Setenv("ALLOWED_METHODS", "GET, POST")
+	origins, methods := corsConfigFromEnv()
+	useCORSConfig(t, origins, methods)
+
+	allowed := corsHeaders("https://app.example.com")
+	if got := allowed.Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("allowed origin: Access-Control-Allow-Origin = %q", got)
+	}
+	if got := allowed.Get("Access-Control-Allow-Methods"); got != "GET, POST" {
+		t.Errorf("Access-Control-Allow-Methods = %q", got)
+	}
+	if got := corsHeaders("https://docs.example.com").Get("Access-Control-Allow-Origin"); got != "https://docs.example.com" {
+		t.Errorf("origin configured with a trailing slash: Access-Control-Allow-Origin = %q", got)
+	}
+
+	denied := corsHeaders("https://evil.example.com")
+	if got := denied.Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("disallowed origin: Access-Control-Allow-Origin = %q", got)
+	}
+	if denied.Get("Vary") != "Origin" {
+		t.Errorf("Vary = %q, want Origin", denied.Get("Vary"))
+	}
+}
+
+func TestCORSWildcardWithoutAllowList(t *testing.T) {
+	useCORSConfig(t, nil, "GET")
+
+	headers := corsHeaders("https://anywhere.example.com")
+	if got := headers.Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+	}
+	if headers.Get("Vary") != "Origin" {
+		t.Errorf("Vary = %q, want Origin", headers.Get("Vary"))
+	}
+}
+
+func TestWikiValidationErrorsHaveCORSHeaders(t *testing.T) {
+	useCORSConfig(t, nil, "GET")
+
+	rec := getWiki(t, "owner=octo&repo=demo&page=a/b")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status %d, want 400", rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+	}
+
+	stubWiki(t, map[string]string{"Home": "# Home"})
+	ok := getWiki(t, "owner=octo&repo=demo")
+	if ok.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", ok.Code, ok.Body.String())
+	}
+	if got := ok.Header().Values("Vary"); len(got) != 1 {
+		t.Errorf("Vary = %q, want a single Origin", got)
+	}
+}
+
+func TestBatchHasCORSHeaders(t *testing.T) {
+	useCORSConfig(t, nil, "GET, POST")
+
+	preflight := httptest.NewRecorder()
+	handleBatchRequest(preflight, httptest.NewRequest(http.MethodOptions, "/readme/batch", nil))
+	if preflight.Code != http.StatusOK {
+		t.Errorf("preflight status %d, want 200", preflight.Code)
+	}
+	if got := preflight.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST" {
+		t.Errorf("Access-Control-Allow-Methods = %q", got)
+	}
+
+	rec, _ := postBatch(t, "", "not json")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status %d, want 400", rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// DebugInfo Raw upstream responses and intermediate HTML for troubleshooting
+type DebugInfo struct {
+	GitHubResponses map[string]json.RawMessage `json:"githubResponses,omitempty"`
+	HTML            string                     `json:"html,omitempty"`
+}
+
+// Collects raw GitHub responses made while processing a request
+type debugCapture struct {
+	mu        sync.Mutex
+	responses map[string]json.RawMessage
+}
+
+type debugCaptureKey struct{}
+
+// Report whether the server allows debug output
+func debugModeEnabled() bool {
+	return os.Getenv("DEBUG") == "true"
+}
+
+// Attach a debug capture to the context
+func withDebugCapture(ctx context.Context) (context.Context, *debugCapture) {
+	capture := &debugCapture{responses: make(map[string]json.RawMessage)}
+	return context.WithValue(ctx, debugCaptureKey{}, capture), capture
+}
+
+// Record a raw GitHub response body if the context is capturing
+func recordDebugResponse(ctx context.Context, name string, body []byte) {
+	capture, ok := ctx.Value(debugCaptureKey{}).(*debugCapture)
+	if !ok {
+		return
+	}
+
+	raw := json.RawMessage(body)
+	if !json.Valid(body) {
+		// Keep non-JSON bodies readable as a string
+		raw, _ = json.Marshal(string(body))
+	}
+
+	capture.mu.Lock()
+	defer capture.mu.Unlock()
+	capture.responses[name] = raw
+}
+
+func (c *debugCapture) snapshot() map[string]json.RawMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	responses := make(map[string]json.RawMessage, len(c.responses))
+	for name, raw := range c.responses {
+		responses[name] = raw
+	}
+	return responses
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestDebugRawWithoutParsedDebugInfo(t *testing.T) {
+	t.Setenv("DEBUG", "true")
+	stubFiles(t, map[string]string{
+		"/repos/octo/demo/readme":           "# Demo\n",
+		"/repos/octo/demo/contents/main.go": "package main\n",
+	})
+
+	rec := getReadme(t, "owner=octo&repo=demo&debugraw=true&format=html", nil)
+	if rec.Code != http.StatusOK {
+		t.Errorf("format=html: status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = getReadme(t, "owner=octo&repo=demo&path=main.go&debugraw=true", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("path=main.go: status %d: %s", rec.Code, rec.Body.String())
+	}
+	var doc MarkdownDocument
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decoding: %v", err)
+	}
+	if doc.Debug == nil || len(doc.Debug.GitHubResponses["file"]) == 0 {
+		t.Errorf("debug = %+v, want the raw file response", doc.Debug)
+	}
+}
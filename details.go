@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Render markdown written inside raw <details> blocks. The markdown parser
+// passes the whole block through as HTML, leaving code fences and lists
+// inside collapsible sections as literal text. The block's source is
+// recovered byte for byte, so text the author escaped stays escaped.
+func expandDetailsMarkdown(ctx context.Context, htmlContent string, md markdownOptions) string {
+	if !strings.Contains(htmlContent, "<details") {
+		return htmlContent
+	}
+
+	var out strings.Builder
+	z := html.NewTokenizer(strings.NewReader(htmlContent))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if err := z.Err(); !errors.Is(err, io.EOF) {
+				logf(ctx, "Error parsing HTML: %v", err)
+				return htmlContent
+			}
+			return out.String()
+		}
+
+		out.Write(z.Raw())
+		if tt == html.StartTagToken && tokenName(z) == "details" {
+			if !expandDetailsBlock(ctx, z, &out, md) {
+				// Unterminated block, keep the rest as it was
+				return htmlContent
+			}
+		}
+	}
+}
+
+// Helper function to copy the body of a <details> block after its start tag,
+// keeping the summary and rendering everything else as markdown. Reports
+// whether the closing tag was found.
+func expandDetailsBlock(ctx context.Context, z *html.Tokenizer, out *strings.Builder, md markdownOptions) bool {
+	var source strings.Builder
+	depth := 0
+	inSummary := false
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return false
+		}
+		raw := z.Raw()
+		name := tokenName(z)
+
+		switch {
+		case tt == html.StartTagToken && name == "details":
+			depth++
+		case tt == html.EndTagToken && name == "details" && depth > 0:
+			depth--
+		case tt == html.EndTagToken && name == "details":
+			if strings.TrimSpace(source.String()) != "" {
+				// Nested blocks are passed through again by the markdown parser
+				out.WriteString(expandDetailsMarkdown(ctx, parseMarkdownToHTML([]byte(source.String()), md), md))
+			}
+			out.Write(raw)
+			return true
+		case depth == 0 && tt == html.StartTagToken && name == "summary":
+			inSummary = true
+		}
+
+		if inSummary {
+			out.Write(raw)
+			if tt == html.EndTagToken && name == "summary" {
+				inSummary = false
+			}
+			continue
+		}
+		source.Write(raw)
+	}
+}
+
+// Helper function to get the tag name of the current token
+func tokenName(z *html.Tokenizer) string {
+	name, _ := z.TagName()
+	return string(name)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDetailsMarkdownIsParsed(t *testing.T) {
+	elements := parseTestMarkdown(t, "<details>\n<summary>Install</summary>\n\n```go\nfmt.Println(1 < 2)\n```\n\n- one\n- two\n\n</details>\n")
+
+	details := findElement(elements, "details")
+	if details == nil {
+		t.Fatalf("no details element in %+v", elements)
+	}
+	if summary := findElement(details.Children, "summary"); summary == nil || summary.Content != "Install" {
+		t.Errorf("summary = %+v, want content %q", summary, "Install")
+	}
+	code := findElement(details.Children, "code_block")
+	if code == nil {
+		t.Fatalf("code fence inside details was not parsed: %+v", details.Children)
+	}
+	if code.Attributes.Language != "go" || code.Content != "fmt.Println(1 < 2)" {
+		t.Errorf("code block = %q (%q), want go code", code.Content, code.Attributes.Language)
+	}
+	if list := findElement(details.Children, "unordered_list"); list == nil || len(list.Children) != 2 {
+		t.Errorf("list inside details = %+v, want two items", list)
+	}
+}
+
+func TestDetailsKeepsEscapedText(t *testing.T) {
+	source := "<details>\n<summary>Tags</summary>\n\nuse a &lt;b&gt; tag, not &lt;script&gt;alert(1)&lt;/script&gt;\n\n</details>\n"
+
+	for _, sanitize := range []bool{true, false} {
+		out := renderContentHTML(context.Background(), source, sanitize, defaultMarkdownOptions())
+		if strings.Contains(out, "<b>") || strings.Contains(out, "<script>") {
+			t.Errorf("sanitize=%t: escaped text became markup: %s", sanitize, out)
+		}
+	}
+
+	elements := parseTestMarkdown(t, source)
+	if findElement(elements, "strong") != nil {
+		t.Errorf("escaped <b> parsed as bold: %+v", elements)
+	}
+}
+
+func TestNestedDetailsAreExpanded(t *testing.T) {
+	elements := parseTestMarkdown(t, "<details><summary>Outer</summary>\n\n<details><summary>Inner</summary>\n\n**deep**\n\n</details>\n\n</details>\n\nafter\n")
+
+	all := findElements(elements, "details")
+	if len(all) != 2 {
+		t.Fatalf("found %d details elements, want 2: %+v", len(all), elements)
+	}
+	if findElement(all[1].Children, "strong") == nil {
+		t.Errorf("markdown inside the nested block was not parsed: %+v", all[1].Children)
+	}
+	if last := elements[len(elements)-1]; last.Type != "paragraph" || plainText(last) != "after" {
+		t.Errorf("content after the block = %+v", last)
+	}
+}
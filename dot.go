@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Longest content shown in a DOT node label
+const dotLabelMaxContent = 40
+
+// RenderDOT Render an element tree as a GraphViz DOT digraph
+func RenderDOT(content []Element) string {
+	var sb strings.Builder
+	sb.WriteString("digraph document {\n")
+	sb.WriteString("  node [shape=box, fontname=\"Helvetica\"];\n")
+	sb.WriteString("  root [label=\"document\", shape=ellipse];\n")
+
+	next := 0
+	var walk func(parent string, elements []Element)
+	walk = func(parent string, elements []Element) {
+		for _, el := range elements {
+			id := fmt.Sprintf("n%d", next)
+			next++
+
+			fmt.Fprintf(&sb, "  %s [label=%s];\n", id, dotQuote(dotLabel(el)))
+			fmt.Fprintf(&sb, "  %s -> %s;\n", parent, id)
+			walk(id, el.Children)
+		}
+	}
+	walk("root", content)
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// Helper function to label a node with its type and shortened content
+func dotLabel(el Element) string {
+	content := strings.Join(strings.Fields(el.Content), " ")
+	if content == "" {
+		return el.Type
+	}
+	if runes := []rune(content); len(runes) > dotLabelMaxContent {
+		content = string(runes[:dotLabelMaxContent]) + "…"
+	}
+	return el.Type + "\n" + content
+}
+
+// Helper function to quote a DOT identifier
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,122 @@
+package main
+
+import "regexp"
+
+// A GitHub emoji shortcode such as :rocket:
+var emojiShortcodePattern = regexp.MustCompile(`:([a-z0-9_+-]+):`)
+
+// Common GitHub emoji shortcodes. Unlisted shortcodes are left as written.
+var emojiShortcodes = map[string]string{
+	"+1":                       "👍",
+	"-1":                       "👎",
+	"100":                      "💯",
+	"alien":                    "👽",
+	"arrow_down":               "⬇️",
+	"arrow_left":               "⬅️",
+	"arrow_right":              "➡️",
+	"arrow_up":                 "⬆️",
+	"art":                      "🎨",
+	"beers":                    "🍻",
+	"bell":                     "🔔",
+	"book":                     "📖",
+	"books":                    "📚",
+	"boom":                     "💥",
+	"bug":                      "🐛",
+	"bulb":                     "💡",
+	"calendar":                 "📆",
+	"chart_with_upwards_trend": "📈",
+	"check":                    "✔️",
+	"clap":                     "👏",
+	"clipboard":                "📋",
+	"coffee":                   "☕",
+	"computer":                 "💻",
+	"construction":             "🚧",
+	"cool":                     "🆒",
+	"crab":                     "🦀",
+	"dart":                     "🎯",
+	"dizzy":                    "💫",
+	"email":                    "📧",
+	"eyes":                     "👀",
+	"fire":                     "🔥",
+	"gear":                     "⚙️",
+	"gem":                      "💎",
+	"gift":                     "🎁",
+	"globe_with_meridians":     "🌐",
+	"hammer":                   "🔨",
+	"hammer_and_wrench":        "🛠️",
+	"heart":                    "❤️",
+	"heavy_check_mark":         "✔️",
+	"hourglass":                "⌛",
+	"house":                    "🏠",
+	"information_source":       "ℹ️",
+	"key":                      "🔑",
+	"label":                    "🏷️",
+	"link":                     "🔗",
+	"lipstick":                 "💄",
+	"lock":                     "🔒",
+	"mag":                      "🔍",
+	"memo":                     "📝",
+	"microscope":               "🔬",
+	"money_with_wings":         "💸",
+	"muscle":                   "💪",
+	"new":                      "🆕",
+	"no_entry":                 "⛔",
+	"ok_hand":                  "👌",
+	"package":                  "📦",
+	"pencil":                   "📝",
+	"pencil2":                  "✏️",
+	"point_right":              "👉",
+	"pray":                     "🙏",
+	"pushpin":                  "📌",
+	"question":                 "❓",
+	"rainbow":                  "🌈",
+	"recycle":                  "♻️",
+	"rocket":                   "🚀",
+	"rotating_light":           "🚨",
+	"scroll":                   "📜",
+	"seedling":                 "🌱",
+	"shield":                   "🛡️",
+	"smile":                    "😄",
+	"smiley":                   "😃",
+	"snake":                    "🐍",
+	"sparkles":                 "✨",
+	"star":                     "⭐",
+	"star2":                    "🌟",
+	"tada":                     "🎉",
+	"test_tube":                "🧪",
+	"thinking":                 "🤔",
+	"thumbsdown":               "👎",
+	"thumbsup":                 "👍",
+	"tools":                    "🛠️",
+	"trophy":                   "🏆",
+	"truck":                    "🚚",
+	"warning":                  "⚠️",
+	"wave":                     "👋",
+	"white_check_mark":         "✅",
+	"wink":                     "😉",
+	"wrench":                   "🔧",
+	"x":                        "❌",
+	"zap":                      "⚡",
+}
+
+// Replace known emoji shortcodes in element text. Code is left as written.
+func applyEmoji(elements []Element) {
+	for i := range elements {
+		el := &elements[i]
+		if el.Type == "code" || el.Type == "code_block" {
+			continue
+		}
+		el.Content = replaceOutsideCode(*el, replaceEmojiShortcodes)
+		applyEmoji(el.Children)
+	}
+}
+
+// Helper function to replace recognized shortcodes in a string
+func replaceEmojiShortcodes(s string) string {
+	return emojiShortcodePattern.ReplaceAllStringFunc(s, func(match string) string {
+		if emoji, ok := emojiShortcodes[match[1:len(match)-1]]; ok {
+			return emoji
+		}
+		return match
+	})
+}
@@ -0,0 +1,55 @@
+package main
+
+import "testing"
+
+// Helper function to parse markdown and apply the options of a query string
+func testDocumentWithOptions(t *testing.T, markdownContent, rawQuery string) MarkdownDocument {
+	t.Helper()
+	return applyDocumentOptions(parseTestMarkdownDoc(t, markdownContent, rawQuery), "octo", "demo", testOptions(t, rawQuery))
+}
+
+func TestEmojiShortcodesOutsideCode(t *testing.T) {
+	doc := testDocumentWithOptions(t, "## Launch :rocket:\n\nShip it :rocket: :not_an_emoji: `:rocket:`\n\n```\n:tada:\n```\n", "")
+
+	if heading := findElement(doc.Content, "heading"); heading == nil || heading.Content != "Launch 🚀" {
+		t.Errorf("heading = %+v, want the shortcode converted", heading)
+	}
+	paragraph := findElement(doc.Content, "paragraph")
+	if paragraph == nil || len(paragraph.Children) != 2 {
+		t.Fatalf("paragraph = %+v, want text and inline code", paragraph)
+	}
+	if got := paragraph.Children[0].Content; got != "Ship it 🚀 :not_an_emoji:" {
+		t.Errorf("text = %q, want known shortcodes converted", got)
+	}
+	if got := paragraph.Children[1].Content; got != ":rocket:" {
+		t.Errorf("inline code = %q, want it verbatim", got)
+	}
+	if block := findElement(doc.Content, "code_block"); block == nil || block.Content != ":tada:" {
+		t.Errorf("code block = %+v, want it verbatim", block)
+	}
+}
+
+func TestEmojiCanBeTurnedOff(t *testing.T) {
+	doc := testDocumentWithOptions(t, "Ship it :rocket:\n", "emoji=false")
+	if text := findElement(doc.Content, "text"); text == nil || text.Content != "Ship it :rocket:" {
+		t.Errorf("text = %+v, want the shortcode kept", text)
+	}
+}
+
+func TestEmojiKeepsInlineCodeInHeadings(t *testing.T) {
+	doc := testDocumentWithOptions(t, "## `:rocket:` setup :tada:\n", "")
+
+	heading := findElement(doc.Content, "heading")
+	if heading == nil {
+		t.Fatal("no heading element")
+	}
+	if heading.Content != ":rocket: setup 🎉" {
+		t.Errorf("heading content = %q, want only the shortcode outside code converted", heading.Content)
+	}
+	if code := findElement(heading.Children, "code"); code == nil || code.Content != ":rocket:" {
+		t.Errorf("code = %+v, want it verbatim", code)
+	}
+	if toc := doc.TableOfContents; len(toc) != 1 || toc[0].Text != heading.Content {
+		t.Errorf("toc = %+v, want the heading text", toc)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Version of the response schema, bumped on breaking changes
+const schemaVersion = "1"
+
+// ResponseEnvelope Optional wrapper around /readme responses
+type ResponseEnvelope struct {
+	Data  any          `json:"data,omitempty"`
+	Error string       `json:"error,omitempty"`
+	Meta  ResponseMeta `json:"meta"`
+}
+
+type ResponseMeta struct {
+	Cached        bool   `json:"cached"`
+	SchemaVersion string `json:"schemaVersion"`
+	TimingMs      int64  `json:"timingMs"`
+}
+
+// Build response metadata for a request that started at start
+func newResponseMeta(start time.Time, cached bool) ResponseMeta {
+	return ResponseMeta{
+		Cached:        cached,
+		SchemaVersion: schemaVersion,
+		TimingMs:      time.Since(start).Milliseconds(),
+	}
+}
+
+// Write data wrapped in an envelope
+func writeEnvelope(w http.ResponseWriter, status int, envelope ResponseEnvelope) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(envelope); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// GitHubAPIError A non-2xx response from GitHub
+type GitHubAPIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *GitHubAPIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("GitHub API returned status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("GitHub API returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// Matches any RateLimitError
+var errRateLimited = errors.New("GitHub rate limit exceeded")
+
+// Returned when a document or GitHub response exceeds MAX_README_BYTES
+var errContentTooLarge = errors.New("content exceeds the size limit")
+
+// RateLimitError GitHub refused a request until the rate limit resets
+type RateLimitError struct {
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%v, resets at %s", errRateLimited, e.Reset.Format(time.RFC3339))
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return errRateLimited
+}
+
+// Helper function to turn a non-2xx GitHub response into a GitHubAPIError,
+// or a RateLimitError when the rate limit is exhausted
+func checkGitHubResponse(resp *http.Response, body []byte) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	limited := resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0")
+	if limited {
+		return &RateLimitError{Reset: rateLimitReset(resp.Header)}
+	}
+
+	var errResp struct {
+		Message string `json:"message"`
+	}
+	// Bodies that are not JSON, like raw file responses, carry no message
+	_ = json.Unmarshal(body, &errResp)
+
+	return &GitHubAPIError{StatusCode: resp.StatusCode, Message: errResp.Message}
+}
+
+// Helper function to read when a rate limit resets, from X-RateLimit-Reset
+// (Unix seconds) or Retry-After (seconds from now)
+func rateLimitReset(header http.Header) time.Time {
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		return time.Unix(reset, 0)
+	}
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil {
+		return time.Now().Add(time.Duration(seconds) * time.Second)
+	}
+	// GitHub asks clients without a hint to wait at least a minute
+	return time.Now().Add(time.Minute)
+}
+
+// Helper function to tell rate-limited clients when to retry
+func setRetryAfter(w http.ResponseWriter, err error) {
+	var limitErr *RateLimitError
+	if !errors.As(err, &limitErr) {
+		return
+	}
+	seconds := int(math.Ceil(time.Until(limitErr.Reset).Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+}
+
+// Helper function to pick the client status and message for a failed upstream call
+func upstreamErrorStatus(err error, name string) (int, string) {
+	if errors.Is(err, errRateLimited) {
+		return http.StatusTooManyRequests, "GitHub rate limit exceeded, retry later"
+	}
+	if errors.Is(err, errContentTooLarge) {
+		return http.StatusRequestEntityTooLarge, fmt.Sprintf("The %s is larger than the %d byte limit", name, maxContentBytes)
+	}
+
+	// Anything else failed on the way to or from GitHub, e.g. a connection
+	// error or a malformed response
+	var apiErr *GitHubAPIError
+	if !errors.As(err, &apiErr) {
+		return http.StatusBadGateway, "Failed to fetch the " + name + " from GitHub"
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusNotFound:
+		return http.StatusNotFound, "Repository or " + name + " not found"
+	case http.StatusUnauthorized:
+		return http.StatusUnauthorized, "GitHub rejected the credentials"
+	case http.StatusForbidden:
+		return http.StatusForbidden, "GitHub denied access to the repository"
+	default:
+		return http.StatusBadGateway, "GitHub request failed"
+	}
+}
+
+// ErrorResponse JSON body of a failed request
+type ErrorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// Stable error codes clients can branch on
+const (
+	errorCodeBadRequest       = "bad_request"
+	errorCodeUnauthorized     = "unauthorized"
+	errorCodeForbidden        = "forbidden"
+	errorCodeNotFound         = "not_found"
+	errorCodeMethodNotAllowed = "method_not_allowed"
+	errorCodeRateLimited      = "rate_limited"
+	errorCodeTooLarge         = "content_too_large"
+	errorCodeUpstream         = "upstream_error"
+	errorCodeUnavailable      = "unavailable"
+	errorCodeInternal         = "internal_error"
+)
+
+// Helper function to pick the error code for a response status
+func errorCodeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return errorCodeBadRequest
+	case http.StatusUnauthorized:
+		return errorCodeUnauthorized
+	case http.StatusForbidden:
+		return errorCodeForbidden
+	case http.StatusNotFound:
+		return errorCodeNotFound
+	case http.StatusMethodNotAllowed:
+		return errorCodeMethodNotAllowed
+	case http.StatusTooManyRequests:
+		return errorCodeRateLimited
+	case http.StatusRequestEntityTooLarge:
+		return errorCodeTooLarge
+	case http.StatusBadGateway:
+		return errorCodeUpstream
+	case http.StatusServiceUnavailable:
+		return errorCodeUnavailable
+	default:
+		return errorCodeInternal
+	}
+}
+
+// Write an error as JSON, the JSON counterpart of http.Error
+func writeJSONError(w http.ResponseWriter, status int, code, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(ErrorResponse{Error: msg, Code: code}); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// Helper function to call the README handler and decode a JSON error
+func getReadmeError(t *testing.T, query string) (int, ErrorResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handleReadmeRequest(rec, httptest.NewRequest(http.MethodGet, "/readme?"+query, nil))
+
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	var errResp ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &errResp); err != nil {
+		t.Fatalf("decoding error body %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, errResp
+}
+
+func TestMissingParamsReturnJSONError(t *testing.T) {
+	status, errResp := getReadmeError(t, "owner=octo")
+	if status != http.StatusBadRequest || errResp.Code != errorCodeBadRequest || errResp.Error == "" {
+		t.Errorf("got %d %+v, want 400 bad_request", status, errResp)
+	}
+}
+
+func TestUpstreamNotFoundReturnsJSONError(t *testing.T) {
+	stubReadmes(t, nil)
+
+	status, errResp := getReadmeError(t, "owner=octo&repo=missing")
+	if status != http.StatusNotFound || errResp.Code != errorCodeNotFound {
+		t.Errorf("got %d %+v, want 404 not_found", status, errResp)
+	}
+}
+
+func TestTransportFailureIsAnUpstreamError(t *testing.T) {
+	srv := stubReadmes(t, nil)
+	srv.Close()
+
+	status, errResp := getReadmeError(t, "owner=octo&repo=demo")
+	if status != http.StatusBadGateway || errResp.Code != errorCodeUpstream {
+		t.Errorf("got %d %+v, want 502 upstream_error", status, errResp)
+	}
+}
+
+func TestGitHubErrorStatusesAreTyped(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusForbidden} {
+		stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			fmt.Fprintf(w, `{"message":%q}`, http.StatusText(status))
+		})
+
+		_, readmeErr := getReadmeContent(context.Background(), "octo", "demo", "")
+		_, metadataErr := getRepositoryMetadata(context.Background(), "octo", "demo")
+		for name, err := range map[string]error{"README": readmeErr, "metadata": metadataErr} {
+			var apiErr *GitHubAPIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != status || apiErr.Message != http.StatusText(status) {
+				t.Errorf("%d %s: err = %v, want a GitHubAPIError", status, name, err)
+			}
+		}
+	}
+}
+
+func TestForbiddenReturnsJSONError(t *testing.T) {
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message":"Resource not accessible by integration"}`)
+	})
+
+	status, errResp := getReadmeError(t, "owner=octo&repo=private")
+	if status != http.StatusForbidden || errResp.Code != errorCodeForbidden {
+		t.Errorf("got %d %+v, want 403 forbidden", status, errResp)
+	}
+}
+
+func TestRateLimitReturns429WithRetryAfter(t *testing.T) {
+	reset := time.Now().Add(90 * time.Second).Unix()
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message":"API rate limit exceeded"}`)
+	})
+
+	rec := httptest.NewRecorder()
+	handleReadmeRequest(rec, httptest.NewRequest(http.MethodGet, "/readme?owner=octo&repo=demo", nil))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status %d, want 429", rec.Code)
+	}
+	var errResp ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &errResp); err != nil || errResp.Code != errorCodeRateLimited {
+		t.Errorf("body %q, want a rate_limited error", rec.Body.String())
+	}
+	retryAfter, err := strconv.Atoi(rec.Header().Get("Retry-After"))
+	if err != nil || retryAfter < 85 || retryAfter > 91 {
+		t.Errorf("Retry-After = %q, want about 90 seconds", rec.Header().Get("Retry-After"))
+	}
+}
+
+func TestRateLimitDetection(t *testing.T) {
+	header := http.Header{"X-Ratelimit-Remaining": {"42"}}
+	resp := &http.Response{StatusCode: http.StatusForbidden, Header: header}
+	if err := checkGitHubResponse(resp, []byte(`{"message":"Forbidden"}`)); errors.Is(err, errRateLimited) {
+		t.Errorf("err = %v, want a plain 403", err)
+	}
+
+	resp = &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"30"}}}
+	var limitErr *RateLimitError
+	if err := checkGitHubResponse(resp, nil); !errors.As(err, &limitErr) || time.Until(limitErr.Reset) > 30*time.Second {
+		t.Errorf("429 with Retry-After: err = %v", err)
+	}
+}
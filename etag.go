@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Default lifetime of remembered GitHub responses
+const defaultETagTTL = time.Hour
+
+// Response GitHub identified with an ETag. Repository responses keep the
+// raw body; READMEs keep the parsed document so a 304 skips parsing.
+type etagEntry struct {
+	etag        string
+	body        []byte
+	doc         MarkdownDocument
+	frontMatter FrontMatter
+	expires     time.Time
+}
+
+// Remembered GitHub responses, revalidated with If-None-Match. Conditional
+// requests answered with 304 do not count against the rate limit.
+type etagStore struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]etagEntry
+}
+
+// Shared store for README and repository responses, configured from
+// ETAG_TTL and CACHE_MAX_ENTRIES at startup
+var githubETags = newETagStore(defaultETagTTL, defaultCacheMaxEntries)
+
+func newETagStore(ttl time.Duration, maxEntries int) *etagStore {
+	return &etagStore{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]etagEntry),
+	}
+}
+
+// Read the lifetime of remembered GitHub responses from the environment
+func etagTTLFromEnv() (time.Duration, error) {
+	raw := os.Getenv("ETAG_TTL")
+	if raw == "" {
+		return defaultETagTTL, nil
+	}
+
+	ttl, err := time.ParseDuration(raw)
+	if err != nil || ttl < 0 {
+		return 0, fmt.Errorf("invalid ETAG_TTL %q", raw)
+	}
+	return ttl, nil
+}
+
+// Get a copy of a remembered response that has not expired
+func (s *etagStore) get(key string) (etagEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return etagEntry{}, false
+	}
+
+	// Callers modify documents in place, so never hand out the stored tree
+	entry.doc.Content = cloneElements(entry.doc.Content)
+	entry.doc.Footnotes = cloneElements(entry.doc.Footnotes)
+	return entry, true
+}
+
+func (s *etagStore) set(key string, entry etagEntry) {
+	if s.ttl <= 0 {
+		return
+	}
+
+	entry.doc.Content = cloneElements(entry.doc.Content)
+	entry.doc.Footnotes = cloneElements(entry.doc.Footnotes)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	entry.expires = now.Add(s.ttl)
+
+	// Entries are cheap to refetch, so when full drop expired entries or,
+	// failing that, an arbitrary one
+	if _, ok := s.entries[key]; !ok && len(s.entries) >= s.maxEntries {
+		for k, e := range s.entries {
+			if now.After(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		for k := range s.entries {
+			if len(s.entries) < s.maxEntries {
+				break
+			}
+			delete(s.entries, k)
+		}
+	}
+	s.entries[key] = entry
+}
+
+// Send a GitHub request revalidating a remembered response. A 304 is
+// returned to the caller as a 200 carrying the remembered body.
+func doConditionalGitHubRequest(ctx context.Context, url, accept string) (*http.Response, []byte, error) {
+	key := accept + "|" + url
+	cached, hasCached := githubETags.get(key)
+
+	resp, body, err := doGitHubRequestWithETag(ctx, url, accept, cached.etag)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && hasCached:
+		resp.StatusCode = http.StatusOK
+		resp.Status = http.StatusText(http.StatusOK)
+		return resp, cached.body, nil
+	case resp.StatusCode == http.StatusOK:
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			githubETags.set(key, etagEntry{etag: etag, body: body})
+		}
+	}
+	return resp, body, nil
+}
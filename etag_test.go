@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Helper function to give a test its own remembered GitHub responses
+func useTestETagStore(t *testing.T, ttl time.Duration, maxEntries int) *etagStore {
+	t.Helper()
+	previous := githubETags
+	githubETags = newETagStore(ttl, maxEntries)
+	t.Cleanup(func() { githubETags = previous })
+	return githubETags
+}
+
+func TestUnchangedReadmeReusesParsedDocument(t *testing.T) {
+	readmeRequests, notModified := 0, 0
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/octo/demo":
+			fmt.Fprint(w, `{"name":"demo","html_url":"https://github.com/octo/demo","default_branch":"main"}`)
+		case "/repos/octo/demo/readme":
+			readmeRequests++
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			content := base64.StdEncoding.EncodeToString([]byte("# Demo\n\nHello"))
+			fmt.Fprintf(w, `{"content":%q,"encoding":"base64"}`, content)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	store := useTestETagStore(t, time.Hour, 10)
+	opts := testOptions(t, "")
+	first, err := fetchReadmeDocument(context.Background(), "octo", "demo", opts)
+	if err != nil {
+		t.Fatalf("first fetch: %v", err)
+	}
+	if heading := findElement(first.Content, "heading"); heading == nil || heading.Content != "Demo" {
+		t.Fatalf("first fetch content = %+v", first.Content)
+	}
+
+	// Mark the remembered document so a reparse would be visible
+	key := "readme|" + readmeAPIURL("octo", "demo", "") + "|" + parseOptionsKey(opts)
+	entry, ok := store.get(key)
+	if !ok {
+		t.Fatal("parsed README was not remembered")
+	}
+	entry.doc.Content = []Element{{Type: "paragraph", Content: "remembered"}}
+	store.set(key, entry)
+
+	second, err := fetchReadmeDocument(context.Background(), "octo", "demo", opts)
+	if err != nil {
+		t.Fatalf("second fetch: %v", err)
+	}
+	if readmeRequests != 2 || notModified != 1 {
+		t.Fatalf("readme requests = %d, 304s = %d, want 2 and 1", readmeRequests, notModified)
+	}
+	if len(second.Content) != 1 || second.Content[0].Content != "remembered" {
+		t.Errorf("second fetch reparsed the README: %+v", second.Content)
+	}
+	if second.Metadata.URL != "https://github.com/octo/demo" {
+		t.Errorf("metadata URL = %q", second.Metadata.URL)
+	}
+}
+
+func TestETagStoreIsBounded(t *testing.T) {
+	store := newETagStore(time.Hour, 2)
+	for i := 0; i < 5; i++ {
+		store.set(fmt.Sprintf("key-%d", i), etagEntry{etag: "tag", body: []byte("body")})
+	}
+	if len(store.entries) != 2 {
+		t.Errorf("store holds %d entries, want 2", len(store.entries))
+	}
+}
+
+func TestETagStoreEntriesExpire(t *testing.T) {
+	store := newETagStore(time.Millisecond, 10)
+	store.set("key", etagEntry{etag: "tag", body: []byte("body")})
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := store.get("key"); ok {
+		t.Error("expired entry was returned")
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	neturl "net/url"
+	"path"
+	"strings"
+)
+
+// File extensions parsed as markdown
+var markdownExtensions = map[string]bool{
+	".md":       true,
+	".markdown": true,
+	".mdown":    true,
+	".mkd":      true,
+	".mkdn":     true,
+}
+
+// Code block language for known source file extensions
+var codeExtensions = map[string]string{
+	".go":    "go",
+	".py":    "python",
+	".js":    "javascript",
+	".mjs":   "javascript",
+	".ts":    "typescript",
+	".tsx":   "tsx",
+	".jsx":   "jsx",
+	".rs":    "rust",
+	".rb":    "ruby",
+	".java":  "java",
+	".kt":    "kotlin",
+	".swift": "swift",
+	".c":     "c",
+	".h":     "c",
+	".cpp":   "cpp",
+	".cs":    "csharp",
+	".php":   "php",
+	".sh":    "bash",
+	".bash":  "bash",
+	".ps1":   "powershell",
+	".sql":   "sql",
+	".json":  "json",
+	".yaml":  "yaml",
+	".yml":   "yaml",
+	".toml":  "toml",
+	".xml":   "xml",
+	".html":  "html",
+	".css":   "css",
+	".tf":    "hcl",
+	".proto": "protobuf",
+	".mod":   "go-module",
+}
+
+// Process a file from the repository, choosing how to parse it by extension
+func processFile(ctx context.Context, owner, repo, filePath string, opts readmeOptions) (MarkdownDocument, error) {
+	content, metadata, err := fetchWithMetadata(ctx, owner, repo, func(ctx context.Context) (string, error) {
+		content, err := getFileContent(ctx, owner, repo, requestRef(owner, repo, opts), filePath)
+		if err != nil {
+			return "", fmt.Errorf("fetching file: %w", err)
+		}
+		return content, nil
+	})
+	if err != nil {
+		return MarkdownDocument{}, err
+	}
+
+	return renderFile(ctx, filePath, content, metadata, opts), nil
+}
+
+// Render file content as markdown, a code block, or plain text
+func renderFile(ctx context.Context, filePath, content string, metadata DocumentMetadata, opts readmeOptions) MarkdownDocument {
+	ext := strings.ToLower(path.Ext(filePath))
+
+	if markdownExtensions[ext] {
+		return renderDocument(ctx, content, metadata, opts)
+	}
+
+	var element Element
+	if language, ok := codeExtensions[ext]; ok {
+		element = Element{
+			Type:    "code_block",
+			Content: content,
+			Attributes: Attributes{
+				Language: language,
+			},
+		}
+	} else {
+		element = Element{
+			Type:    "text",
+			Content: content,
+		}
+	}
+
+	hash := sha256.Sum256([]byte(content))
+
+	return MarkdownDocument{
+		Metadata:    metadata,
+		Content:     []Element{element},
+		RawContent:  content,
+		ContentHash: hex.EncodeToString(hash[:]),
+	}
+}
+
+// Fetch a file from the repository via the contents API
+func getFileContent(ctx context.Context, owner, repo, ref, filePath string) (string, error) {
+	segments := strings.Split(filePath, "/")
+	for i, segment := range segments {
+		segments[i] = neturl.PathEscape(segment)
+	}
+	url := githubAPIURL("/repos/%s/%s/contents/%s", neturl.PathEscape(owner), neturl.PathEscape(repo), strings.Join(segments, "/"))
+	if ref != "" {
+		url += "?ref=" + neturl.QueryEscape(ref)
+	}
+
+	resp, body, err := doGitHubRequest(ctx, url, githubJSONMediaType)
+	if err != nil {
+		return "", err
+	}
+	recordDebugResponse(ctx, "file", body)
+	if err := checkGitHubResponse(resp, body); err != nil {
+		return "", err
+	}
+
+	var fileResp struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	if err := json.Unmarshal(body, &fileResp); err != nil {
+		return "", fmt.Errorf("parsing response: %w", err)
+	}
+
+	return decodeContent(fileResp.Content)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// Helper function to serve repository files through the contents API
+func stubFiles(t *testing.T, files map[string]string) *[]string {
+	t.Helper()
+	var requested []string
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		if r.URL.Path == "/repos/octo/demo" {
+			fmt.Fprint(w, `{"name":"demo","html_url":"https://github.com/octo/demo","default_branch":"main"}`)
+			return
+		}
+		content, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"content":%q,"encoding":"base64"}`, base64.StdEncoding.EncodeToString([]byte(content)))
+	})
+	return &requested
+}
+
+func TestNestedMarkdownPathIsRendered(t *testing.T) {
+	requested := stubFiles(t, map[string]string{
+		"/repos/octo/demo/contents/docs/architecture.md": "# Architecture\n\n![diagram](diagram.png)\n",
+	})
+
+	rec := getReadme(t, "owner=octo&repo=demo&path=docs/architecture.md", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+	}
+	var doc MarkdownDocument
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decoding: %v", err)
+	}
+	if heading := findElement(doc.Content, "heading"); heading == nil || heading.Content != "Architecture" {
+		t.Errorf("content = %+v, want the file's heading", doc.Content)
+	}
+	// Relative images resolve against the file's directory
+	if image := findElement(doc.Content, "image"); image == nil || image.Attributes.Src != "https://raw.githubusercontent.com/octo/demo/main/docs/diagram.png" {
+		t.Errorf("image = %+v", image)
+	}
+	for _, p := range *requested {
+		if p == "/repos/octo/demo/readme" {
+			t.Error("the README endpoint was called for a path request")
+		}
+	}
+}
+
+func TestUnsafePathsAreRejected(t *testing.T) {
+	requested := stubFiles(t, nil)
+
+	for _, p := range []string{"../secret", "docs/../../secret", "/etc/passwd", `docs\..\secret`} {
+		status, errResp := getReadmeError(t, "owner=octo&repo=demo&path="+p)
+		if status != http.StatusBadRequest || errResp.Code != errorCodeBadRequest {
+			t.Errorf("path %q: got %d %+v, want 400", p, status, errResp)
+		}
+	}
+	if len(*requested) != 0 {
+		t.Errorf("rejected paths reached GitHub: %v", *requested)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Helper function to read the footnote id a <sup class="footnote-ref"> marker points to
+func footnoteRefID(n *html.Node) (string, bool) {
+	if !strings.Contains(" "+getAttr(n, "class")+" ", " footnote-ref ") {
+		return "", false
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == "a" {
+			if id, ok := strings.CutPrefix(getAttr(c, "href"), "#fn:"); ok {
+				return id, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Helper function to detect the footnote definitions block
+func isFootnotesBlock(n *html.Node) bool {
+	return strings.Contains(" "+getAttr(n, "class")+" ", " footnotes ")
+}
+
+// Helper function to find the definition items of a footnotes block
+func footnoteItems(n *html.Node) []*html.Node {
+	var items []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		if c.Data == "li" && strings.HasPrefix(getAttr(c, "id"), "fn:") {
+			items = append(items, c)
+			continue
+		}
+		items = append(items, footnoteItems(c)...)
+	}
+	return items
+}
+
+// Move footnote definitions out of the content, returning the remaining
+// content and the definitions in document order
+func extractFootnotes(elements []Element) ([]Element, []Element) {
+	var content, footnotes []Element
+	for _, el := range elements {
+		if el.Type == "footnotes" {
+			footnotes = append(footnotes, el.Children...)
+			continue
+		}
+		content = append(content, el)
+	}
+	return content, footnotes
+}
@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestFootnotesAreCollected(t *testing.T) {
+	source := "Fast[^speed] and small[^size].\n\n[^speed]: Benchmarked on CI.\n[^size]: Under **1 MB**.\n"
+	doc := parseTestMarkdownDoc(t, source, "footnotes=true")
+
+	refs := findElements(doc.Content, "footnote_ref")
+	if len(refs) != 2 {
+		t.Fatalf("footnote refs = %+v, want two", refs)
+	}
+	for i, want := range []struct{ id, marker string }{{"speed", "1"}, {"size", "2"}} {
+		if refs[i].Attributes.ID != want.id || refs[i].Content != want.marker {
+			t.Errorf("ref %d = %q %q, want %q %q", i, refs[i].Attributes.ID, refs[i].Content, want.id, want.marker)
+		}
+	}
+
+	if len(doc.Footnotes) != 2 {
+		t.Fatalf("footnotes = %+v, want two definitions", doc.Footnotes)
+	}
+	for i, want := range []struct{ id, text string }{{"speed", "Benchmarked on CI.\n"}, {"size", "Under 1 MB.\n"}} {
+		footnote := doc.Footnotes[i]
+		if footnote.Type != "footnote" || footnote.Attributes.ID != want.id || RenderPlainText(footnote.Children) != want.text {
+			t.Errorf("footnote %d = %+v, want %q with %q", i, footnote, want.id, want.text)
+		}
+	}
+	if findElement(doc.Footnotes[1].Children, "strong") == nil {
+		t.Error("footnote lost its formatting")
+	}
+	if findElement(doc.Content, "footnotes") != nil || findElement(doc.Content, "footnote") != nil {
+		t.Error("footnote definitions were left in the content")
+	}
+}
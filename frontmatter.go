@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FrontMatter Recognized keys of a leading YAML front matter block
+type FrontMatter struct {
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	Author      string `yaml:"author"`
+}
+
+// Split a leading "---" delimited YAML block from markdown content.
+// Content without valid front matter is returned unchanged.
+func splitFrontMatter(content string) (FrontMatter, string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimPrefix(content, "\uFEFF"), "---")
+	if !ok {
+		return FrontMatter{}, content, false
+	}
+	rest, ok = strings.CutPrefix(strings.TrimLeft(rest, " \t"), "\n")
+	if !ok {
+		rest, ok = strings.CutPrefix(rest, "\r\n")
+		if !ok {
+			return FrontMatter{}, content, false
+		}
+	}
+
+	// The block closes with a line of "---" or "..."
+	offset := 0
+	for _, line := range strings.SplitAfter(rest, "\n") {
+		if trimmed := strings.TrimRight(line, " \t\r\n"); trimmed == "---" || trimmed == "..." {
+			var fm FrontMatter
+			if err := yaml.Unmarshal([]byte(rest[:offset]), &fm); err != nil {
+				return FrontMatter{}, content, false
+			}
+			return fm, rest[offset+len(line):], true
+		}
+		offset += len(line)
+	}
+	return FrontMatter{}, content, false
+}
+
+// Helper function to let front matter values override repository metadata
+func applyFrontMatter(metadata *DocumentMetadata, fm FrontMatter) {
+	if fm.Title != "" {
+		metadata.Title = fm.Title
+	}
+	if fm.Description != "" {
+		metadata.Description = fm.Description
+	}
+	if fm.Author != "" {
+		metadata.Author = fm.Author
+	}
+}
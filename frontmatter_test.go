@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFrontMatterOverridesMetadata(t *testing.T) {
+	source := "---\ntitle: Architecture\ndescription: How it fits together\nauthor: Docs Team\ntags: [design]\n---\n# Overview\n"
+	doc := renderDocument(context.Background(), source, DocumentMetadata{Title: "repo", Description: "from GitHub", Author: "owner"}, testOptions(t, ""))
+
+	if doc.Metadata.Title != "Architecture" || doc.Metadata.Description != "How it fits together" || doc.Metadata.Author != "Docs Team" {
+		t.Errorf("metadata = %+v, want front matter values", doc.Metadata)
+	}
+	if doc.RawContent != "# Overview\n" {
+		t.Errorf("RawContent = %q, want the body without front matter", doc.RawContent)
+	}
+	if len(doc.Content) != 1 || doc.Content[0].Type != "heading" {
+		t.Errorf("content = %+v, want only the heading", doc.Content)
+	}
+}
+
+func TestWithoutFrontMatterContentIsUnchanged(t *testing.T) {
+	tests := []string{
+		"# Title\n\n---\n\ntext\n",
+		"---\nnot: [closed\n---\ntext\n",
+		"---\nno closing delimiter\n",
+	}
+	for _, source := range tests {
+		doc := renderDocument(context.Background(), source, DocumentMetadata{Title: "repo"}, testOptions(t, ""))
+		if doc.RawContent != source || doc.Metadata.Title != "repo" {
+			t.Errorf("source %q: RawContent = %q, title = %q", source, doc.RawContent, doc.Metadata.Title)
+		}
+	}
+}
+
+func TestFrontMatterRobotsNoIndex(t *testing.T) {
+	doc := renderDocument(context.Background(), "---\nrobots: noindex\n---\n# Private notes\n", DocumentMetadata{}, testOptions(t, ""))
+	if !doc.Metadata.NoIndex {
+		t.Error("NoIndex = false, want true for robots: noindex front matter")
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Retry policy for transient GitHub failures
+const (
+	maxGitHubAttempts    = 3
+	githubRetryBaseDelay = 250 * time.Millisecond
+)
+
+// Default cap on decoded document size
+const defaultMaxContentBytes = 5 << 20
+
+// Document size cap, configured from MAX_README_BYTES at startup
+var maxContentBytes = defaultMaxContentBytes
+
+// Helper function to bound GitHub response bodies. Contents arrive base64
+// encoded in JSON, wrapped at 60 characters, plus some room for metadata.
+func maxResponseBytes() int64 {
+	encoded := int64(maxContentBytes+2) / 3 * 4
+	return encoded + encoded/60 + 64<<10
+}
+
+// Media type of GitHub REST API responses
+const githubJSONMediaType = "application/vnd.github.v3+json"
+
+// Default GitHub REST API root
+const defaultGitHubAPIBase = "https://api.github.com"
+
+// API root, configured from GITHUB_API_BASE at startup for GitHub Enterprise
+var githubAPIBase = defaultGitHubAPIBase
+
+// Read the API root from GITHUB_API_BASE, e.g. https://ghe.example.com/api/v3
+func githubAPIBaseFromEnv() (string, error) {
+	raw := os.Getenv("GITHUB_API_BASE")
+	if raw == "" {
+		return defaultGitHubAPIBase, nil
+	}
+
+	u, err := neturl.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid GITHUB_API_BASE %q, expected an http(s) URL", raw)
+	}
+	return strings.TrimRight(raw, "/"), nil
+}
+
+// Build a GitHub API URL from a path format such as "/repos/%s/%s"
+func githubAPIURL(format string, args ...any) string {
+	return githubAPIBase + fmt.Sprintf(format, args...)
+}
+
+// Helper function to tell whether a URL belongs to the configured GitHub.
+// Tokens are never sent to other hosts, such as github.com raw content
+// while the API base points at GitHub Enterprise.
+func sendsGitHubToken(rawURL string) bool {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	base, err := neturl.Parse(githubAPIBase)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Host, base.Host) {
+		return true
+	}
+	return githubAPIBase == defaultGitHubAPIBase && strings.EqualFold(u.Host, githubRawHost)
+}
+
+// Host serving raw repository and wiki content for github.com
+const githubRawHost = "raw.githubusercontent.com"
+
+// Root of raw github.com content that wiki pages are fetched from
+var githubRawBase = "https://" + githubRawHost
+
+// Send an authenticated GET request to GitHub and read the response body.
+// Connection errors and 502/503/504 responses are retried with exponential
+// backoff; other responses are returned for the caller to check.
+func doGitHubRequest(ctx context.Context, url, accept string) (*http.Response, []byte, error) {
+	return doGitHubRequestWithETag(ctx, url, accept, "")
+}
+
+// Same as doGitHubRequest, sending If-None-Match when etag is set
+func doGitHubRequestWithETag(ctx context.Context, url, accept, etag string) (*http.Response, []byte, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	var lastErr error
+	for attempt := 0; attempt < maxGitHubAttempts; attempt++ {
+		if attempt > 0 {
+			logf(ctx, "Retrying GitHub request %s: %v", url, lastErr)
+			if err := sleepBackoff(ctx, attempt); err != nil {
+				return nil, nil, fmt.Errorf("%w (after %v)", err, lastErr)
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("creating request: %w", err)
+		}
+		if sendsGitHubToken(url) {
+			req.Header.Set("Authorization", "token "+githubToken(ctx))
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, nil, fmt.Errorf("making request: %w", err)
+			}
+			lastErr = fmt.Errorf("making request: %w", err)
+			continue
+		}
+
+		limit := maxResponseBytes()
+		body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			logf(ctx, "Error closing response body: %v", closeErr)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading response: %w", err)
+		}
+		if int64(len(body)) > limit {
+			return nil, nil, fmt.Errorf("reading response: %w", errContentTooLarge)
+		}
+
+		if isTransientStatus(resp.StatusCode) && attempt < maxGitHubAttempts-1 {
+			lastErr = fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+			continue
+		}
+		return resp, body, nil
+	}
+
+	return nil, nil, lastErr
+}
+
+// Helper function to tell gateway errors worth retrying from real failures
+func isTransientStatus(status int) bool {
+	return status == http.StatusBadGateway ||
+		status == http.StatusServiceUnavailable ||
+		status == http.StatusGatewayTimeout
+}
+
+// Helper function to wait before a retry, doubling the delay each attempt
+// with up to 50% jitter. Returns early when the context is done.
+func sleepBackoff(ctx context.Context, attempt int) error {
+	delay := githubRetryBaseDelay << (attempt - 1)
+	delay += time.Duration(rand.Int64N(int64(delay/2) + 1))
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return errors.Join(errors.New("retry canceled"), ctx.Err())
+	}
+}
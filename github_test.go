@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGitHubAPIBaseFromEnv(t *testing.T) {
+	t.Setenv("GITHUB_API_BASE", "https://ghe.example.com/api/v3/")
+	base, err := githubAPIBaseFromEnv()
+	if err != nil {
+		t.Fatalf("githubAPIBaseFromEnv: %v", err)
+	}
+	if base != "https://ghe.example.com/api/v3" {
+		t.Errorf("base = %q, want the trailing slash trimmed", base)
+	}
+
+	t.Setenv("GITHUB_API_BASE", "ftp://ghe.example.com")
+	if _, err := githubAPIBaseFromEnv(); err == nil {
+		t.Error("non-http base was accepted")
+	}
+}
+
+func TestRequestsUseConfiguredAPIBase(t *testing.T) {
+	var paths []string
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/repos/octo/demo":
+			fmt.Fprint(w, `{"name":"demo","default_branch":"main"}`)
+		case "/repos/octo/demo/readme":
+			fmt.Fprintf(w, `{"content":%q}`, base64.StdEncoding.EncodeToString([]byte("# Demo")))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	if _, err := getReadmeContent(context.Background(), "octo", "demo", ""); err != nil {
+		t.Fatalf("getReadmeContent: %v", err)
+	}
+	metadata, err := getRepositoryMetadata(context.Background(), "octo", "demo")
+	if err != nil {
+		t.Fatalf("getRepositoryMetadata: %v", err)
+	}
+	if metadata.Ref != "main" {
+		t.Errorf("metadata ref = %q, want main", metadata.Ref)
+	}
+	if len(paths) != 2 || paths[0] != "/repos/octo/demo/readme" || paths[1] != "/repos/octo/demo" {
+		t.Errorf("requested paths = %v", paths)
+	}
+}
+
+func TestTokenIsOnlySentToConfiguredHost(t *testing.T) {
+	var authorization string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("GITHUB_TOKEN", "secret")
+
+	previous := githubAPIBase
+	t.Cleanup(func() { githubAPIBase = previous })
+
+	githubAPIBase = "https://ghe.example.com/api/v3"
+	if _, _, err := doGitHubRequest(context.Background(), srv.URL+"/wiki/page.md", ""); err != nil {
+		t.Fatalf("doGitHubRequest: %v", err)
+	}
+	if authorization != "" {
+		t.Errorf("token sent to a host other than the API base: %q", authorization)
+	}
+
+	githubAPIBase = srv.URL
+	if _, _, err := doGitHubRequest(context.Background(), srv.URL+"/repos/octo/demo", ""); err != nil {
+		t.Fatalf("doGitHubRequest: %v", err)
+	}
+	if authorization != "token secret" {
+		t.Errorf("authorization = %q, want the token", authorization)
+	}
+}
+
+func TestRawContentHostOnlyTrustedForGitHubDotCom(t *testing.T) {
+	previous := githubAPIBase
+	t.Cleanup(func() { githubAPIBase = previous })
+
+	githubAPIBase = defaultGitHubAPIBase
+	if !sendsGitHubToken("https://raw.githubusercontent.com/wiki/octo/demo/Home.md") {
+		t.Error("github.com raw content did not get the token")
+	}
+
+	githubAPIBase = "https://ghe.example.com/api/v3"
+	if sendsGitHubToken("https://raw.githubusercontent.com/wiki/octo/demo/Home.md") {
+		t.Error("Enterprise token was sent to github.com raw content")
+	}
+}
+
+func TestOversizedReadmeIsRejected(t *testing.T) {
+	previous := maxContentBytes
+	maxContentBytes = 1 << 10
+	t.Cleanup(func() { maxContentBytes = previous })
+
+	// Larger than the response limit, so reading stops early
+	served := 0
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/readme") {
+			fmt.Fprint(w, `{"name":"demo"}`)
+			return
+		}
+		served++
+		encoded := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("x", 1<<20)))
+		fmt.Fprintf(w, `{"content":%q}`, encoded)
+	})
+
+	rec := getReadme(t, "owner=octo&repo=demo", nil)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", rec.Code)
+	}
+	if served != 1 {
+		t.Errorf("README served %d times, want no retries", served)
+	}
+}
+
+func TestDecodedContentIsLimited(t *testing.T) {
+	previous := maxContentBytes
+	maxContentBytes = 8
+	t.Cleanup(func() { maxContentBytes = previous })
+
+	if _, err := decodeContent(base64.StdEncoding.EncodeToString([]byte("123456789"))); !errors.Is(err, errContentTooLarge) {
+		t.Errorf("9 bytes: err = %v, want errContentTooLarge", err)
+	}
+	if _, err := decodeContent(base64.StdEncoding.EncodeToString([]byte("12345678"))); err != nil {
+		t.Errorf("8 bytes: err = %v", err)
+	}
+}
+
+func TestReadmeAndMetadataAreFetchedConcurrently(t *testing.T) {
+	const delay = 200 * time.Millisecond
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		if strings.HasSuffix(r.URL.Path, "/readme") {
+			fmt.Fprintf(w, `{"content":%q}`, base64.StdEncoding.EncodeToString([]byte("# Demo")))
+			return
+		}
+		fmt.Fprint(w, `{"name":"demo"}`)
+	})
+
+	start := time.Now()
+	rec := getReadme(t, "owner=octo&repo=demo", nil)
+	elapsed := time.Since(start)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+	}
+	if elapsed >= 2*delay {
+		t.Errorf("handler took %v, want close to %v rather than %v", elapsed, delay, 2*delay)
+	}
+}
+
+func TestFailedFetchCancelsTheOther(t *testing.T) {
+	metadataDone := make(chan error, 1)
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/readme") {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+			return
+		}
+		select {
+		case <-r.Context().Done():
+			metadataDone <- r.Context().Err()
+		case <-time.After(2 * time.Second):
+			metadataDone <- nil
+			fmt.Fprint(w, `{"name":"demo"}`)
+		}
+	})
+
+	if rec := getReadme(t, "owner=octo&repo=demo", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("status %d, want 404", rec.Code)
+	}
+	if err := <-metadataDone; err == nil {
+		t.Error("metadata request ran to completion after the README failed")
+	}
+}
+
+func TestTransientFailuresAreRetried(t *testing.T) {
+	attempts := 0
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"name":"demo"}`)
+	})
+
+	metadata, err := getRepositoryMetadata(context.Background(), "octo", "demo")
+	if err != nil {
+		t.Fatalf("getRepositoryMetadata: %v", err)
+	}
+	if metadata.Repository != "octo/demo" || attempts != 3 {
+		t.Errorf("repository %q after %d attempts, want octo/demo after 3", metadata.Repository, attempts)
+	}
+
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.NotFound(w, r)
+	})
+	attempts = 0
+	if _, err := getRepositoryMetadata(context.Background(), "octo", "demo"); err == nil || attempts != 1 {
+		t.Errorf("404: err = %v after %d attempts, want one attempt", err, attempts)
+	}
+}
+
+func TestRetriesStopWhenTheContextIsDone(t *testing.T) {
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, _, err := doGitHubRequest(ctx, githubAPIBase+"/repos/octo/demo", "")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want the deadline", err)
+	}
+	if elapsed := time.Since(start); elapsed > githubRetryBaseDelay {
+		t.Errorf("gave up after %v, want before the first backoff ends", elapsed)
+	}
+}
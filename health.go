@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// HealthStatus Probe result
+type HealthStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// HTTP Handler for liveness and readiness probes. With ?deep=true the
+// token is checked against GitHub's rate limit endpoint, which costs no quota.
+func handleHealthRequest(w http.ResponseWriter, r *http.Request) {
+	status := http.StatusOK
+	health := HealthStatus{Status: "ok"}
+
+	if r.URL.Query().Get("deep") == "true" {
+		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+		defer cancel()
+
+		if err := checkGitHubConnectivity(ctx); err != nil {
+			logf(ctx, "Error checking GitHub connectivity: %v", err)
+			status = http.StatusServiceUnavailable
+			health = HealthStatus{Status: "unavailable", Error: "GitHub is unreachable or rejected the token"}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(health); err != nil {
+		logf(r.Context(), "Error encoding response: %v", err)
+	}
+}
+
+// Make an authenticated call to GitHub to confirm the token works
+func checkGitHubConnectivity(ctx context.Context) error {
+	resp, body, err := doGitHubRequest(ctx, githubAPIURL("/rate_limit"), githubJSONMediaType)
+	if err != nil {
+		return err
+	}
+	return checkGitHubResponse(resp, body)
+}
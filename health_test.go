@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Helper function to call the health handler and decode its status
+func getHealth(t *testing.T, query string) (int, HealthStatus) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handleHealthRequest(rec, httptest.NewRequest(http.MethodGet, "/healthz?"+query, nil))
+
+	var health HealthStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &health); err != nil {
+		t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, health
+}
+
+func TestShallowHealthMakesNoGitHubCalls(t *testing.T) {
+	calls := 0
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	status, health := getHealth(t, "")
+	if status != http.StatusOK || health.Status != "ok" || calls != 0 {
+		t.Errorf("got %d %+v after %d GitHub calls, want ok without calls", status, health, calls)
+	}
+}
+
+func TestDeepHealthChecksTheToken(t *testing.T) {
+	var authorization string
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if r.URL.Path != "/rate_limit" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"resources":{}}`))
+	})
+
+	status, health := getHealth(t, "deep=true")
+	if status != http.StatusOK || health.Status != "ok" {
+		t.Errorf("got %d %+v, want ok", status, health)
+	}
+	if authorization != "token test-token" {
+		t.Errorf("Authorization = %q, want the configured token", authorization)
+	}
+}
+
+func TestDeepHealthFailsWhenGitHubRejectsTheToken(t *testing.T) {
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message":"Bad credentials"}`))
+	})
+
+	status, health := getHealth(t, "deep=true")
+	if status != http.StatusServiceUnavailable || health.Status != "unavailable" || health.Error == "" {
+		t.Errorf("got %d %+v, want 503 unavailable", status, health)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+// Line highlight annotation in a fence info string, e.g. "go {1,3-5}"
+var highlightLinesPattern = regexp.MustCompile(`\{([\d\s,-]+)\}`)
+
+// Attach highlighted line numbers from fence info strings to code blocks.
+// Code blocks are matched to the markdown AST by their content, as raw HTML
+// <pre> blocks become code blocks too but are not fences.
+func applyHighlightLines(elements []Element, markdownContent []byte, md markdownOptions) {
+	mdParser := parser.NewWithExtensions(md.extensions())
+	root := mdParser.Parse(markdownContent)
+
+	// Info strings of the fences with each content, in document order
+	infos := make(map[string][]string)
+	ast.WalkFunc(root, func(node ast.Node, entering bool) ast.WalkStatus {
+		if block, ok := node.(*ast.CodeBlock); ok && entering {
+			content := strings.TrimSuffix(string(block.Literal), "\n")
+			infos[content] = append(infos[content], string(block.Info))
+		}
+		return ast.GoToNext
+	})
+
+	var walk func([]Element)
+	walk = func(elements []Element) {
+		for i := range elements {
+			if elements[i].Type == "code_block" {
+				if pending := infos[elements[i].Content]; len(pending) > 0 {
+					elements[i].Attributes.HighlightLines = parseHighlightLines(pending[0])
+					infos[elements[i].Content] = pending[1:]
+				}
+				continue
+			}
+			// Markdown inside <details> is not part of the document AST
+			if elements[i].Type == "details" {
+				continue
+			}
+			walk(elements[i].Children)
+		}
+	}
+	walk(elements)
+}
+
+// Helper function to expand "{1,3-5}" into [1 3 4 5]
+func parseHighlightLines(info string) []int {
+	match := highlightLinesPattern.FindStringSubmatch(info)
+	if match == nil {
+		return nil
+	}
+
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(match[1], ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(first))
+		if err != nil || start < 1 {
+			continue
+		}
+		end := start
+		if isRange {
+			end, err = strconv.Atoi(strings.TrimSpace(last))
+			if err != nil || end < start {
+				continue
+			}
+		}
+
+		for line := start; line <= end; line++ {
+			seen[line] = true
+		}
+	}
+
+	lines := make([]int, 0, len(seen))
+	for line := range seen {
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+
+	return lines
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseHighlightLines(t *testing.T) {
+	tests := map[string][]int{
+		"go {1,3-5}":   {1, 3, 4, 5},
+		"{2, 2, 1}":    {1, 2},
+		"python {5-3}": {},
+		"go":           nil,
+		"js {0,7}":     {7},
+	}
+	for info, want := range tests {
+		if got := parseHighlightLines(info); !slices.Equal(got, want) {
+			t.Errorf("parseHighlightLines(%q) = %v, want %v", info, got, want)
+		}
+	}
+}
+
+func TestCodeBlocksCarryHighlightLines(t *testing.T) {
+	elements := parseTestMarkdown(t, "```go {1,3-5}\npackage main\n\nfunc main() {\n}\n```\n\n```\nplain\n```\n")
+
+	blocks := findElements(elements, "code_block")
+	if len(blocks) != 2 {
+		t.Fatalf("got %d code blocks, want 2", len(blocks))
+	}
+	if !slices.Equal(blocks[0].Attributes.HighlightLines, []int{1, 3, 4, 5}) {
+		t.Errorf("highlight lines = %v, want [1 3 4 5]", blocks[0].Attributes.HighlightLines)
+	}
+	if blocks[0].Attributes.Language != "go" || blocks[0].Content != "package main\n\nfunc main() {\n}" {
+		t.Errorf("code block = %+v, want clean go code", blocks[0])
+	}
+	if blocks[1].Attributes.HighlightLines != nil {
+		t.Errorf("plain block highlight lines = %v", blocks[1].Attributes.HighlightLines)
+	}
+}
+
+func TestRawPreDoesNotShiftHighlightLines(t *testing.T) {
+	source := "<pre>raw block</pre>\n\n```go {2}\nfirst\nsecond\n```\n\n```js {1}\nthird\n```\n"
+	blocks := findElements(parseTestMarkdown(t, source), "code_block")
+	if len(blocks) != 3 {
+		t.Fatalf("got %d code blocks, want 3", len(blocks))
+	}
+	if blocks[0].Attributes.HighlightLines != nil {
+		t.Errorf("raw pre highlight lines = %v, want none", blocks[0].Attributes.HighlightLines)
+	}
+	if !slices.Equal(blocks[1].Attributes.HighlightLines, []int{2}) {
+		t.Errorf("go block highlight lines = %v, want [2]", blocks[1].Attributes.HighlightLines)
+	}
+	if !slices.Equal(blocks[2].Attributes.HighlightLines, []int{1}) {
+		t.Errorf("js block highlight lines = %v, want [1]", blocks[2].Attributes.HighlightLines)
+	}
+}
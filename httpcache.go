@@ -0,0 +1,73 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Default max-age advertised to clients and CDNs
+const defaultResponseMaxAge = 60 * time.Second
+
+// Client cache lifetime, configured from RESPONSE_MAX_AGE at startup
+var responseMaxAge = defaultResponseMaxAge
+
+// Read the client cache lifetime from the environment
+func responseMaxAgeFromEnv() (time.Duration, error) {
+	raw := os.Getenv("RESPONSE_MAX_AGE")
+	if raw == "" {
+		return defaultResponseMaxAge, nil
+	}
+
+	maxAge, err := time.ParseDuration(raw)
+	if err != nil || maxAge < 0 {
+		return 0, fmt.Errorf("invalid RESPONSE_MAX_AGE %q", raw)
+	}
+	return maxAge, nil
+}
+
+// Write a response body with a strong ETag and Cache-Control, answering a
+// matching If-None-Match with 304. Private responses, e.g. fetched with a
+// caller's own token, are kept out of shared caches.
+func writeCacheable(w http.ResponseWriter, r *http.Request, body []byte, private bool) {
+	writeTaggedCacheable(w, r, body, body, private)
+}
+
+// Same as writeCacheable, deriving the ETag from tagged instead of the body
+// for responses carrying per-request details
+func writeTaggedCacheable(w http.ResponseWriter, r *http.Request, body, tagged []byte, private bool) {
+	hash := sha256.Sum256(tagged)
+	etag := `"` + hex.EncodeToString(hash[:16]) + `"`
+
+	visibility := "public"
+	if private {
+		visibility = "private"
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", visibility, int(responseMaxAge.Seconds())))
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.Header().Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if _, err := w.Write(body); err != nil {
+		logf(r.Context(), "Error writing response: %v", err)
+	}
+}
+
+// Helper function to check an If-None-Match header against an ETag
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
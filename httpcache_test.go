@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadmeResponsesCarryCacheHeaders(t *testing.T) {
+	stubReadmes(t, map[string]string{"octo/demo": "# Demo"})
+
+	rec := getReadme(t, "owner=octo&repo=demo", nil)
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" {
+		t.Fatalf("status %d, ETag %q", rec.Code, etag)
+	}
+	if got := rec.Header().Get("Cache-Control"); !strings.HasPrefix(got, "public, max-age=") {
+		t.Errorf("Cache-Control = %q", got)
+	}
+
+	rec = getReadme(t, "owner=octo&repo=demo", http.Header{"If-None-Match": {etag}})
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("matching If-None-Match: status %d, body %q, want an empty 304", rec.Code, rec.Body.String())
+	}
+
+	rec = getReadme(t, "owner=octo&repo=demo", http.Header{"If-None-Match": {`"other"`}})
+	if rec.Code != http.StatusOK {
+		t.Errorf("stale If-None-Match: status %d, want 200", rec.Code)
+	}
+}
+
+func TestCallerTokenResponsesArePrivate(t *testing.T) {
+	stubReadmes(t, map[string]string{"octo/demo": "# Demo"})
+
+	rec := getReadme(t, "owner=octo&repo=demo", http.Header{"X-Github-Token": {"caller"}})
+	if got := rec.Header().Get("Cache-Control"); !strings.HasPrefix(got, "private, ") {
+		t.Errorf("Cache-Control = %q, want private", got)
+	}
+}
+
+func TestEnvelopeETagIgnoresTiming(t *testing.T) {
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		// Slow enough that timingMs differs between requests
+		time.Sleep(5 * time.Millisecond)
+		if strings.HasSuffix(r.URL.Path, "/readme") {
+			w.Write([]byte(`{"content":"IyBEZW1v"}`))
+			return
+		}
+		w.Write([]byte(`{"name":"demo"}`))
+	})
+	first := getReadme(t, "owner=octo&repo=demo&envelope=true", nil)
+	second := getReadme(t, "owner=octo&repo=demo&envelope=true", nil)
+	if first.Code != http.StatusOK || second.Code != http.StatusOK {
+		t.Fatalf("status %d and %d", first.Code, second.Code)
+	}
+	if first.Header().Get("ETag") != second.Header().Get("ETag") {
+		t.Errorf("envelope ETag changed between identical requests: %q, %q",
+			first.Header().Get("ETag"), second.Header().Get("ETag"))
+	}
+	if !strings.Contains(second.Body.String(), `"cached":true`) {
+		t.Errorf("second request was not served from the cache: %s", second.Body.String())
+	}
+	if !strings.Contains(first.Body.String(), `"timingMs"`) {
+		t.Errorf("envelope lost its timing: %s", first.Body.String())
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Fill in missing image alt text from the image filename
+func applyAltFallback(elements []Element) {
+	for i := range elements {
+		if elements[i].Type == "image" && elements[i].Attributes.Alt == "" {
+			elements[i].Attributes.Alt = altFromSrc(elements[i].Attributes.Src)
+		}
+		applyAltFallback(elements[i].Children)
+	}
+}
+
+// Helper function to humanize an image filename, e.g. "my_logo.png" -> "my logo"
+func altFromSrc(src string) string {
+	if u, err := url.Parse(src); err == nil {
+		src = u.Path
+	}
+
+	name := path.Base(src)
+	if name == "." || name == "/" {
+		return ""
+	}
+	name = strings.TrimSuffix(name, path.Ext(name))
+
+	name = strings.NewReplacer("-", " ", "_", " ", ".", " ").Replace(name)
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// ImageInfo Image referenced by a README
+type ImageInfo struct {
+	Src    string `json:"src"`
+	Alt    string `json:"alt,omitempty"`
+	Width  string `json:"width,omitempty"`
+	Height string `json:"height,omitempty"`
+}
+
+// Hosts that only serve status badges
+var badgeHosts = []string{
+	"img.shields.io",
+	"badge.fury.io",
+	"badgen.net",
+	"codecov.io",
+	"coveralls.io",
+	"travis-ci.org",
+	"travis-ci.com",
+	"circleci.com",
+	"goreportcard.com",
+	"pkg.go.dev",
+	"app.codacy.com",
+	"snyk.io",
+}
+
+// ExtractImages Collect content images in document order, skipping badges
+func ExtractImages(content []Element) []ImageInfo {
+	var images []ImageInfo
+
+	var walk func([]Element)
+	walk = func(elements []Element) {
+		for _, el := range elements {
+			if el.Type == "image" && !isBadgeImage(el.Attributes) {
+				images = append(images, ImageInfo{
+					Src:    el.Attributes.Src,
+					Alt:    el.Attributes.Alt,
+					Width:  el.Attributes.Width,
+					Height: el.Attributes.Height,
+				})
+			}
+			walk(el.Children)
+		}
+	}
+	walk(content)
+
+	return images
+}
+
+// Helper function to recognize badge images by URL or size
+func isBadgeImage(attrs Attributes) bool {
+	if u, err := url.Parse(attrs.Src); err == nil {
+		host := strings.ToLower(u.Hostname())
+		for _, badgeHost := range badgeHosts {
+			if host == badgeHost || strings.HasSuffix(host, "."+badgeHost) {
+				return true
+			}
+		}
+		if strings.Contains(strings.ToLower(u.Path), "badge") {
+			return true
+		}
+	}
+
+	// Badges are rendered small, typically 20px high
+	if height, err := strconv.Atoi(strings.TrimSuffix(attrs.Height, "px")); err == nil && height <= 32 {
+		return true
+	}
+
+	return false
+}
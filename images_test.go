@@ -0,0 +1,71 @@
+package main
+
+import "testing"
+
+func TestAltFallbackFromFilename(t *testing.T) {
+	tests := map[string]string{
+		"logo.png":                           "logo",
+		"docs/my_cool-screenshot.v2.png":     "my cool screenshot v2",
+		"https://example.com/a/hero.jpg?x=1": "hero",
+		"":                                   "",
+	}
+	for src, want := range tests {
+		if got := altFromSrc(src); got != want {
+			t.Errorf("altFromSrc(%q) = %q, want %q", src, got, want)
+		}
+	}
+}
+
+func TestAltFallbackIsOptIn(t *testing.T) {
+	source := "![](images/project_logo.png)\n\n![Kept](other.png)\n"
+
+	off := applyDocumentOptions(parseTestMarkdownDoc(t, source, ""), "octo", "demo", testOptions(t, ""))
+	if image := findElement(off.Content, "image"); image == nil || image.Attributes.Alt != "" {
+		t.Errorf("without altfallback the alt changed: %+v", image)
+	}
+
+	opts := testOptions(t, "altfallback=true")
+	on := applyDocumentOptions(parseTestMarkdownDoc(t, source, "altfallback=true"), "octo", "demo", opts)
+	images := findElements(on.Content, "image")
+	if len(images) != 2 || images[0].Attributes.Alt != "project logo" || images[1].Attributes.Alt != "Kept" {
+		t.Errorf("images = %+v, want derived and kept alt text", images)
+	}
+}
+
+func TestImageLoadingHintsAreKept(t *testing.T) {
+	source := `<img src="shot.png" alt="Screenshot" loading="lazy" decoding="async">` + "\n\n![Plain](plain.png)\n"
+	images := findElements(parseTestMarkdown(t, source), "image")
+	if len(images) != 2 {
+		t.Fatalf("images = %+v, want two", images)
+	}
+	if images[0].Attributes.Loading != "lazy" || images[0].Attributes.Decoding != "async" {
+		t.Errorf("hinted image attributes = %+v", images[0].Attributes)
+	}
+	if images[1].Attributes.Loading != "" || images[1].Attributes.Decoding != "" {
+		t.Errorf("plain image gained hints: %+v", images[1].Attributes)
+	}
+}
+
+func TestImageAndLinkTitles(t *testing.T) {
+	source := `<img src="arch.png" alt="Architecture" title="Overview" width="640" height="480">` + "\n\n" +
+		`[docs](https://example.com "Read the docs")` + "\n\n" +
+		`![Logo](logo.png "Project logo")` + "\n"
+	elements := parseTestMarkdown(t, source)
+
+	image := findElement(elements, "image")
+	if image == nil {
+		t.Fatal("no image element")
+	}
+	want := Attributes{Src: "arch.png", Alt: "Architecture", Title: "Overview", Width: "640", Height: "480"}
+	got := image.Attributes
+	if got.Src != want.Src || got.Alt != want.Alt || got.Title != want.Title || got.Width != want.Width || got.Height != want.Height {
+		t.Errorf("image attributes = %+v", got)
+	}
+
+	if images := findElements(elements, "image"); len(images) != 2 || images[1].Attributes.Title != "Project logo" {
+		t.Errorf("images = %+v, want the markdown title", images)
+	}
+	if link := findElement(elements, "link"); link == nil || link.Attributes.Title != "Read the docs" {
+		t.Errorf("link = %+v, want its title", link)
+	}
+}
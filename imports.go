@@ -0,0 +1,121 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	goSingleImportPattern = regexp.MustCompile(`(?m)^\s*import\s+(?:[\w.]+\s+)?"([^"]+)"`)
+	goImportBlockPattern  = regexp.MustCompile(`(?ms)^\s*import\s*\((.*?)\)`)
+	goQuotedPathPattern   = regexp.MustCompile(`"([^"]+)"`)
+
+	pythonImportPattern     = regexp.MustCompile(`(?m)^\s*import\s+([\w., ]+)`)
+	pythonFromImportPattern = regexp.MustCompile(`(?m)^\s*from\s+([\w.]+)\s+import\s`)
+
+	jsImportFromPattern = regexp.MustCompile(`(?m)^\s*import\s[^'"]*?\sfrom\s+['"]([^'"]+)['"]`)
+	jsBareImportPattern = regexp.MustCompile(`(?m)^\s*import\s+['"]([^'"]+)['"]`)
+	jsRequirePattern    = regexp.MustCompile(`\b(?:require|import)\(\s*['"]([^'"]+)['"]\s*\)`)
+)
+
+// Attach imported packages to code blocks in languages we understand
+func applyCodeImports(elements []Element) {
+	for i := range elements {
+		if elements[i].Type == "code_block" {
+			elements[i].Attributes.Imports = extractImports(elements[i].Attributes.Language, elements[i].Content)
+		}
+		applyCodeImports(elements[i].Children)
+	}
+}
+
+// Extract the imports of a code snippet, guessing the language when unknown
+func extractImports(language, code string) []string {
+	switch normalizeImportLanguage(language, code) {
+	case "go":
+		return extractGoImports(code)
+	case "python":
+		return extractPythonImports(code)
+	case "javascript":
+		return extractJavaScriptImports(code)
+	}
+	return nil
+}
+
+// Helper function to map fence languages, or the code itself, to an import syntax
+func normalizeImportLanguage(language, code string) string {
+	switch strings.ToLower(language) {
+	case "go", "golang":
+		return "go"
+	case "py", "python", "python3":
+		return "python"
+	case "js", "javascript", "jsx", "mjs", "ts", "typescript", "tsx":
+		return "javascript"
+	case "":
+		// Unlabelled blocks, guess from distinctive syntax
+		switch {
+		case strings.Contains(code, "package ") || goImportBlockPattern.MatchString(code):
+			return "go"
+		case jsImportFromPattern.MatchString(code) || jsRequirePattern.MatchString(code):
+			return "javascript"
+		case pythonFromImportPattern.MatchString(code) || pythonImportPattern.MatchString(code):
+			return "python"
+		}
+	}
+	return ""
+}
+
+func extractGoImports(code string) []string {
+	var imports []string
+	for _, match := range goSingleImportPattern.FindAllStringSubmatch(code, -1) {
+		imports = append(imports, match[1])
+	}
+	for _, block := range goImportBlockPattern.FindAllStringSubmatch(code, -1) {
+		for _, match := range goQuotedPathPattern.FindAllStringSubmatch(block[1], -1) {
+			imports = append(imports, match[1])
+		}
+	}
+	return uniqueSorted(imports)
+}
+
+func extractPythonImports(code string) []string {
+	var imports []string
+	for _, match := range pythonFromImportPattern.FindAllStringSubmatch(code, -1) {
+		imports = append(imports, match[1])
+	}
+	for _, match := range pythonImportPattern.FindAllStringSubmatch(code, -1) {
+		// "import a, b as c" imports a and b
+		for _, name := range strings.Split(match[1], ",") {
+			if fields := strings.Fields(name); len(fields) > 0 {
+				imports = append(imports, fields[0])
+			}
+		}
+	}
+	return uniqueSorted(imports)
+}
+
+func extractJavaScriptImports(code string) []string {
+	var imports []string
+	for _, pattern := range []*regexp.Regexp{jsImportFromPattern, jsBareImportPattern, jsRequirePattern} {
+		for _, match := range pattern.FindAllStringSubmatch(code, -1) {
+			imports = append(imports, match[1])
+		}
+	}
+	return uniqueSorted(imports)
+}
+
+// Helper function to sort and deduplicate strings
+func uniqueSorted(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	sort.Strings(values)
+
+	unique := values[:1]
+	for _, v := range values[1:] {
+		if v != unique[len(unique)-1] {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestExtractImports(t *testing.T) {
+	tests := []struct {
+		language string
+		code     string
+		want     []string
+	}{
+		{"go", "import \"fmt\"\n\nimport (\n\t\"net/http\"\n\tyaml \"gopkg.in/yaml.v3\"\n)\n", []string{"fmt", "gopkg.in/yaml.v3", "net/http"}},
+		{"python", "import os, sys\nfrom collections import OrderedDict\n", []string{"collections", "os", "sys"}},
+		{"js", "import React from 'react'\nimport './styles.css'\nconst fs = require(\"fs\")\n", []string{"./styles.css", "fs", "react"}},
+		{"rust", "use std::io;\n", nil},
+	}
+	for _, tt := range tests {
+		if got := extractImports(tt.language, tt.code); !slices.Equal(got, tt.want) {
+			t.Errorf("extractImports(%q) = %v, want %v", tt.language, got, tt.want)
+		}
+	}
+}
+
+func TestImportsAreAttachedToCodeBlocks(t *testing.T) {
+	doc := renderDocument(context.Background(), "```go\nimport \"fmt\"\n```\n", DocumentMetadata{}, testOptions(t, "imports=true"))
+	applyCodeImports(doc.Content)
+
+	code := findElement(doc.Content, "code_block")
+	if code == nil || !slices.Equal(code.Attributes.Imports, []string{"fmt"}) {
+		t.Errorf("code block = %+v, want imports [fmt]", code)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"io"
+	"net/http"
+)
+
+// Static landing page with usage examples
+const landingPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>README parser</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; }
+code, pre { background: #f4f4f4; border-radius: 4px; }
+pre { padding: 0.75rem; overflow-x: auto; }
+</style>
+</head>
+<body>
+<h1>README parser</h1>
+<p>Fetches a GitHub repository README and returns it as structured JSON.</p>
+<h2>Usage</h2>
+<pre><code>GET /readme?owner=golang&amp;repo=go</code></pre>
+<p>Render the README as markdown or plain text instead:</p>
+<pre><code>GET /readme?owner=golang&amp;repo=go&amp;format=markdown</code></pre>
+<p>Render a wiki page:</p>
+<pre><code>GET /wiki?owner=golang&amp;repo=go&amp;page=Home</code></pre>
+<p>The full API is described at <a href="/openapi.json">/openapi.json</a>.</p>
+</body>
+</html>
+`
+
+// HTTP Handler for the landing page
+func handleLandingRequest(w http.ResponseWriter, r *http.Request) {
+	// "/" matches every unregistered path
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := io.WriteString(w, landingPage); err != nil {
+		logf(r.Context(), "Error writing response: %v", err)
+	}
+}
+
+// HTTP Handler for browsers asking for a favicon
+func handleFaviconRequest(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"time"
+)
+
+// Returned when GitHub detects no license file in the repository
+var errLicenseNotFound = errors.New("no license detected")
+
+// LicenseDocument The license file of a repository
+type LicenseDocument struct {
+	SPDXID  string `json:"spdxId"`
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// HTTP Handler for license file requests
+func handleLicenseRequest(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	setCORSHeaders(w, r)
+	w.Header().Set("Content-Type", "application/json")
+
+	// Handle preflight requests
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	owner := r.URL.Query().Get("owner")
+	repo := r.URL.Query().Get("repo")
+	if owner == "" || repo == "" {
+		writeJSONError(w, http.StatusBadRequest, errorCodeBadRequest, "Owner and repository are required")
+		return
+	}
+	if err := validateRepository(owner, repo); err != nil {
+		writeJSONError(w, http.StatusBadRequest, errorCodeBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(withRequestToken(r.Context(), r), 30*time.Second)
+	defer cancel()
+
+	license, err := getLicense(ctx, owner, repo)
+	if errors.Is(err, errLicenseNotFound) {
+		writeJSONError(w, http.StatusNotFound, errorCodeNotFound, "No license detected for this repository")
+		return
+	}
+	if err != nil {
+		logf(ctx, "Error processing license: %v", err)
+		status, msg := upstreamErrorStatus(err, "license")
+		setRetryAfter(w, err)
+		writeJSONError(w, status, errorCodeForStatus(status), msg)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(license); err != nil {
+		logf(ctx, "Error encoding response: %v", err)
+	}
+}
+
+// Fetch the license file GitHub detected for a repository
+func getLicense(ctx context.Context, owner, repo string) (LicenseDocument, error) {
+	url := githubAPIURL("/repos/%s/%s/license", neturl.PathEscape(owner), neturl.PathEscape(repo))
+
+	resp, body, err := doGitHubRequest(ctx, url, githubJSONMediaType)
+	if err != nil {
+		return LicenseDocument{}, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return LicenseDocument{}, errLicenseNotFound
+	}
+	if err := checkGitHubResponse(resp, body); err != nil {
+		return LicenseDocument{}, err
+	}
+
+	var licenseResp struct {
+		Path     string `json:"path"`
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+		License  struct {
+			SPDXID string `json:"spdx_id"`
+			Name   string `json:"name"`
+		} `json:"license"`
+	}
+	if err := json.Unmarshal(body, &licenseResp); err != nil {
+		return LicenseDocument{}, fmt.Errorf("parsing response: %w", err)
+	}
+
+	content, err := decodeContent(licenseResp.Content)
+	if err != nil {
+		return LicenseDocument{}, err
+	}
+
+	return LicenseDocument{
+		SPDXID:  licenseResp.License.SPDXID,
+		Name:    licenseResp.License.Name,
+		Path:    licenseResp.Path,
+		Content: content,
+	}, nil
+}
@@ -1,63 +1,150 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
+	neturl "net/url"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gomarkdown/markdown"
 	"github.com/gomarkdown/markdown/parser"
 	"golang.org/x/net/html"
+	"golang.org/x/sync/errgroup"
 )
 
 // MarkdownDocument Comprehensive Markdown Element Structures
 type MarkdownDocument struct {
-	Metadata   DocumentMetadata `json:"metadata"`
-	Content    []Element        `json:"content"`
-	RawContent string           `json:"rawContent"`
+	Metadata        DocumentMetadata  `json:"metadata"`
+	Content         []Element         `json:"content"`
+	RawContent      string            `json:"rawContent"`
+	ContentHash     string            `json:"contentHash"`
+	TableOfContents []TOCEntry        `json:"tableOfContents,omitempty"`
+	Anchors         map[string]string `json:"anchors,omitempty"`
+	Pagination      *Pagination       `json:"pagination,omitempty"`
+	Warnings        []string          `json:"warnings,omitempty"`
+	AnchorFixes     []AnchorFix       `json:"anchorFixes,omitempty"`
+	DetectedTopics  []string          `json:"detectedTopics,omitempty"`
+	Documents       [][]Element       `json:"documents,omitempty"`
+	Debug           *DebugInfo        `json:"debug,omitempty"`
+	Images          []ImageInfo       `json:"images,omitempty"`
+	SectionChanges  []SectionChange   `json:"sectionChanges,omitempty"`
+	APIReference    []Element         `json:"apiReference,omitempty"`
+	Footnotes       []Element         `json:"footnotes,omitempty"`
+
+	// Set when the document was served from the cache
+	fromCache bool
+
+	// GitHub page the document is rendered on, used for permalinks
+	pageURL string
+
+	// Sanitized HTML of the content, set instead of Content for format=html
+	renderedHTML string
 }
 
 type DocumentMetadata struct {
-	Title       string    `json:"title"`
-	Repository  string    `json:"repository"`
-	LastUpdated time.Time `json:"lastUpdated"`
-	Author      string    `json:"author"`
-	Description string    `json:"description"`
+	Title              string        `json:"title"`
+	Repository         string        `json:"repository"`
+	URL                string        `json:"url,omitempty"`
+	Ref                string        `json:"ref,omitempty"`
+	LastUpdated        time.Time     `json:"lastUpdated"`
+	Author             string        `json:"author"`
+	Description        string        `json:"description"`
+	Language           string        `json:"language,omitempty"`
+	Topics             []string      `json:"topics,omitempty"`
+	Archived           bool          `json:"archived"`
+	Disabled           bool          `json:"disabled"`
+	NoIndex            bool          `json:"noIndex"`
+	TaskProgress       *TaskProgress `json:"taskProgress,omitempty"`
+	WordCount          int           `json:"wordCount"`
+	ReadingTimeMinutes int           `json:"readingTimeMinutes"`
+	Release            *ReleaseInfo  `json:"release,omitempty"`
 }
 
 type Element struct {
-	Type       string     `json:"type"`
-	Content    string     `json:"content,omitempty"`
-	Children   []Element  `json:"children,omitempty"`
-	Attributes Attributes `json:"attributes,omitempty"`
+	Type           string     `json:"type"`
+	Content        string     `json:"content,omitempty"`
+	Children       []Element  `json:"children,omitempty"`
+	Attributes     Attributes `json:"attributes,omitempty"`
+	SourceMarkdown string     `json:"sourceMarkdown,omitempty"`
+	TextContent    string     `json:"textContent,omitempty"`
+	Line           int        `json:"line,omitempty"`
 }
 
 type Attributes struct {
-	Href   string `json:"href,omitempty"`
-	Src    string `json:"src,omitempty"`
-	Alt    string `json:"alt,omitempty"`
-	Title  string `json:"title,omitempty"`
-	Width  string `json:"width,omitempty"`
-	Height string `json:"height,omitempty"`
-	Level  string `json:"level,omitempty"`
+	Href           string            `json:"href,omitempty"`
+	Src            string            `json:"src,omitempty"`
+	Alt            string            `json:"alt,omitempty"`
+	Title          string            `json:"title,omitempty"`
+	Width          string            `json:"width,omitempty"`
+	Height         string            `json:"height,omitempty"`
+	Loading        string            `json:"loading,omitempty"`
+	Decoding       string            `json:"decoding,omitempty"`
+	Level          string            `json:"level,omitempty"`
+	ID             string            `json:"id,omitempty"`
+	Language       string            `json:"language,omitempty"`
+	HighlightLines []int             `json:"highlightLines,omitempty"`
+	Imports        []string          `json:"imports,omitempty"`
+	Data           map[string]string `json:"data,omitempty"`
+	Format         string            `json:"format,omitempty"`
+	SVG            string            `json:"svg,omitempty"`
+	Datetime       string            `json:"datetime,omitempty"`
+	Permalink      string            `json:"permalink,omitempty"`
+	Signature      string            `json:"signature,omitempty"`
+	Truncated      bool              `json:"truncated,omitempty"`
+	Kind           string            `json:"kind,omitempty"`
+	Checked        *bool             `json:"checked,omitempty"`
+	Align          string            `json:"align,omitempty"`
 }
 
-// Markdown Parsing Function
-func parseMarkdownToHTML(markdownContent []byte) string {
-	// Configure Markdown parser
+// Markdown parser features that can be toggled per request
+type markdownOptions struct {
+	HardLineBreaks bool
+	Tables         bool
+	Footnotes      bool
+}
+
+// Markdown features used for READMEs unless a request changes them
+func defaultMarkdownOptions() markdownOptions {
+	return markdownOptions{
+		HardLineBreaks: true,
+		Tables:         true,
+	}
+}
+
+// Markdown parser extensions for the enabled features
+func (o markdownOptions) extensions() parser.Extensions {
 	extensions := parser.CommonExtensions |
 		parser.AutoHeadingIDs |
-		parser.HardLineBreak |
 		parser.NoEmptyLineBeforeBlock
 
-	mdParser := parser.NewWithExtensions(extensions)
+	if o.HardLineBreaks {
+		extensions |= parser.HardLineBreak
+	}
+	if !o.Tables {
+		extensions &^= parser.Tables
+	}
+	if o.Footnotes {
+		extensions |= parser.Footnotes
+	}
+	return extensions
+}
+
+// Markdown Parsing Function
+func parseMarkdownToHTML(markdownContent []byte, md markdownOptions) string {
+	// Configure Markdown parser
+	mdParser := parser.NewWithExtensions(md.extensions())
 
 	// Convert markdown to HTML
 	htmlContent := markdown.ToHTML(markdownContent, mdParser, nil)
@@ -65,12 +152,18 @@ func parseMarkdownToHTML(markdownContent []byte) string {
 	return string(htmlContent)
 }
 
+// Default limit on HTML nesting followed while parsing
+const defaultMaxNestingDepth = 100
+
+// Nesting limit, configured from MAX_NESTING_DEPTH at startup
+var maxNestingDepth = defaultMaxNestingDepth
+
 // HTML Parsing Function
-func parseHTMLToElements(htmlContent string) []Element {
+func parseHTMLToElements(ctx context.Context, htmlContent string) []Element {
 	// Create a new HTML tokenizer
 	doc, err := html.Parse(strings.NewReader(htmlContent))
 	if err != nil {
-		log.Printf("Error parsing HTML: %v", err)
+		logf(ctx, "Error parsing HTML: %v", err)
 		return []Element{}
 	}
 
@@ -78,6 +171,28 @@ func parseHTMLToElements(htmlContent string) []Element {
 
 	// Recursive function to traverse HTML nodes
 	var traverse func(*html.Node) []Element
+
+	// Traverse every child of a node in order, replacing content nested
+	// deeper than maxNestingDepth with a truncation marker
+	depth := 0
+	traverseChildren := func(n *html.Node) []Element {
+		if n.FirstChild == nil {
+			return nil
+		}
+		if depth >= maxNestingDepth {
+			return []Element{{Type: "truncated", Attributes: Attributes{Truncated: true}}}
+		}
+
+		depth++
+		defer func() { depth-- }()
+
+		var children []Element
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			children = append(children, traverse(c)...)
+		}
+		return children
+	}
+
 	traverse = func(n *html.Node) []Element {
 		if n == nil {
 			return []Element{}
@@ -88,16 +203,21 @@ func parseHTMLToElements(htmlContent string) []Element {
 		// Process different node types
 		switch nodeType := n.Type; nodeType {
 		case html.ElementNode:
+			// Each case builds its element from the node's children, so a
+			// subtree is visited exactly once
+			transparent := false
 			switch n.Data {
 			case "h1", "h2", "h3", "h4", "h5", "h6":
 				// Heading
 				level := strings.TrimPrefix(n.Data, "h")
 				element := Element{
 					Type:    "heading",
-					Content: extractNodeText(n),
+					Content: extractAllText(n),
 					Attributes: Attributes{
 						Level: level,
+						ID:    getAttr(n, "id"),
 					},
+					Children: traverseChildren(n),
 				}
 				nodeElements = append(nodeElements, element)
 
@@ -105,7 +225,7 @@ func parseHTMLToElements(htmlContent string) []Element {
 				// Paragraph
 				para := Element{
 					Type:     "paragraph",
-					Children: traverse(n.FirstChild),
+					Children: traverseChildren(n),
 				}
 				nodeElements = append(nodeElements, para)
 
@@ -115,9 +235,10 @@ func parseHTMLToElements(htmlContent string) []Element {
 				link := Element{
 					Type: "link",
 					Attributes: Attributes{
-						Href: href,
+						Href:  href,
+						Title: getAttr(n, "title"),
 					},
-					Children: traverse(n.FirstChild),
+					Children: traverseChildren(n),
 				}
 				nodeElements = append(nodeElements, link)
 
@@ -126,12 +247,74 @@ func parseHTMLToElements(htmlContent string) []Element {
 				img := Element{
 					Type: "image",
 					Attributes: Attributes{
-						Src: getAttr(n, "src"),
-						Alt: getAttr(n, "alt"),
+						Src:      getAttr(n, "src"),
+						Alt:      getAttr(n, "alt"),
+						Title:    getAttr(n, "title"),
+						Width:    getAttr(n, "width"),
+						Height:   getAttr(n, "height"),
+						Loading:  getAttr(n, "loading"),
+						Decoding: getAttr(n, "decoding"),
 					},
 				}
 				nodeElements = append(nodeElements, img)
 
+			case "hr":
+				// Horizontal rule
+				rule := Element{
+					Type: "horizontal_rule",
+				}
+				nodeElements = append(nodeElements, rule)
+
+			case "br":
+				// Hard line break
+				lineBreak := Element{
+					Type: "line_break",
+				}
+				nodeElements = append(nodeElements, lineBreak)
+
+			case "g-emoji":
+				// GitHub emoji, falling back to the shortcode alias
+				emoji := extractNodeText(n)
+				if emoji == "" && getAttr(n, "alias") != "" {
+					emoji = ":" + getAttr(n, "alias") + ":"
+				}
+				text := Element{
+					Type:    "text",
+					Content: emoji,
+				}
+				nodeElements = append(nodeElements, text)
+
+			case "svg":
+				// Inline SVG, typically a badge
+				svg, ok := safeInlineSVG(n, sanitizePolicy)
+				if !ok {
+					logf(ctx, "Dropping unsafe or oversized inline SVG")
+					break
+				}
+				img := Element{
+					Type: "image",
+					Attributes: Attributes{
+						Alt:    svgAltText(n),
+						Width:  getAttr(n, "width"),
+						Height: getAttr(n, "height"),
+						Format: "svg-inline",
+						SVG:    svg,
+					},
+				}
+				nodeElements = append(nodeElements, img)
+
+			case "time":
+				// Machine-readable date
+				datetime := getAttr(n, "datetime")
+				timeElement := Element{
+					Type:    "time",
+					Content: extractAllText(n),
+					Attributes: Attributes{
+						Datetime: datetime,
+					},
+				}
+				nodeElements = append(nodeElements, timeElement)
+
 			case "code":
 				// Inline code
 				code := Element{
@@ -141,18 +324,29 @@ func parseHTMLToElements(htmlContent string) []Element {
 				nodeElements = append(nodeElements, code)
 
 			case "pre":
-				// Code block
+				// Code block, verbatim apart from the newline closing the last line
 				codeBlock := Element{
 					Type:    "code_block",
-					Content: extractNodeText(n),
+					Content: strings.TrimSuffix(extractRawText(n), "\n"),
+					Attributes: Attributes{
+						Language: codeBlockLanguage(n),
+					},
 				}
 				nodeElements = append(nodeElements, codeBlock)
 
+			case "del", "s":
+				// Strikethrough text
+				strikethrough := Element{
+					Type:     "strikethrough",
+					Children: traverseChildren(n),
+				}
+				nodeElements = append(nodeElements, strikethrough)
+
 			case "strong", "b":
 				// Bold text
 				strong := Element{
 					Type:     "strong",
-					Children: traverse(n.FirstChild),
+					Children: traverseChildren(n),
 				}
 				nodeElements = append(nodeElements, strong)
 
@@ -160,7 +354,7 @@ func parseHTMLToElements(htmlContent string) []Element {
 				// Italic text
 				em := Element{
 					Type:     "emphasis",
-					Children: traverse(n.FirstChild),
+					Children: traverseChildren(n),
 				}
 				nodeElements = append(nodeElements, em)
 
@@ -168,7 +362,7 @@ func parseHTMLToElements(htmlContent string) []Element {
 				// Unordered list
 				list := Element{
 					Type:     "unordered_list",
-					Children: traverse(n.FirstChild),
+					Children: traverseChildren(n),
 				}
 				nodeElements = append(nodeElements, list)
 
@@ -176,7 +370,7 @@ func parseHTMLToElements(htmlContent string) []Element {
 				// Ordered list
 				list := Element{
 					Type:     "ordered_list",
-					Children: traverse(n.FirstChild),
+					Children: traverseChildren(n),
 				}
 				nodeElements = append(nodeElements, list)
 
@@ -184,15 +378,146 @@ func parseHTMLToElements(htmlContent string) []Element {
 				// List item
 				listItem := Element{
 					Type:     "list_item",
-					Children: traverse(n.FirstChild),
+					Children: trimItemLineBreaks(traverseChildren(n)),
+				}
+
+				// GFM task list item, from a checkbox input or a literal [x] marker
+				checked, isTask := leadingCheckbox(n)
+				if !isTask {
+					checked, isTask = stripTaskMarker(listItem.Children)
+				}
+				if isTask {
+					listItem.Type = "task_list_item"
+					listItem.Attributes.Checked = &checked
+					listItem.Children = dropEmptyText(listItem.Children)
 				}
 				nodeElements = append(nodeElements, listItem)
 
+			case "dl":
+				// Definition list. Each term keeps its inline children, followed by
+				// its descriptions in document order.
+				list := Element{
+					Type: "definition_list",
+				}
+				for c := n.FirstChild; c != nil; c = c.NextSibling {
+					if c.Type != html.ElementNode {
+						continue
+					}
+					switch c.Data {
+					case "dt":
+						term := Element{
+							Type:     "definition_term",
+							Content:  strings.Join(strings.Fields(extractAllText(c)), " "),
+							Children: trimTrailingLineBreak(traverseChildren(c)),
+						}
+						list.Children = append(list.Children, term)
+					case "dd":
+						description := Element{
+							Type:     "definition_description",
+							Children: trimTrailingLineBreak(traverseChildren(c)),
+						}
+						if last := len(list.Children) - 1; last >= 0 && list.Children[last].Type == "definition_term" {
+							list.Children[last].Children = append(list.Children[last].Children, description)
+						} else {
+							list.Children = append(list.Children, description)
+						}
+					}
+				}
+				nodeElements = append(nodeElements, list)
+
+			case "blockquote":
+				// GitHub alert written as a [!KIND] blockquote
+				if kind := blockquoteAlertKind(n); kind != "" {
+					admonition := Element{
+						Type: "admonition",
+						Attributes: Attributes{
+							Kind: kind,
+						},
+						Children: stripAlertMarker(traverseChildren(n)),
+					}
+					nodeElements = append(nodeElements, admonition)
+					break
+				}
+
+				// Block quote
+				quote := Element{
+					Type:     "blockquote",
+					Children: traverseChildren(n),
+				}
+				nodeElements = append(nodeElements, quote)
+
+			case "div":
+				// GitHub alert as rendered by the markdown API
+				if kind := divAlertKind(n); kind != "" {
+					admonition := Element{
+						Type: "admonition",
+						Attributes: Attributes{
+							Kind: kind,
+						},
+					}
+					for c := n.FirstChild; c != nil; c = c.NextSibling {
+						if !isAlertTitle(c) {
+							admonition.Children = append(admonition.Children, traverse(c)...)
+						}
+					}
+					nodeElements = append(nodeElements, admonition)
+				} else if isFootnotesBlock(n) {
+					// Footnote definitions, moved out of the content later
+					footnotes := Element{
+						Type: "footnotes",
+					}
+					for _, item := range footnoteItems(n) {
+						footnote := Element{
+							Type:     "footnote",
+							Children: trimTrailingLineBreak(traverseChildren(item)),
+							Attributes: Attributes{
+								ID: strings.TrimPrefix(getAttr(item, "id"), "fn:"),
+							},
+						}
+						footnotes.Children = append(footnotes.Children, footnote)
+					}
+					nodeElements = append(nodeElements, footnotes)
+				} else {
+					transparent = true
+				}
+
+			case "sup":
+				// Footnote reference marker
+				if id, ok := footnoteRefID(n); ok {
+					ref := Element{
+						Type:    "footnote_ref",
+						Content: strings.TrimSpace(extractAllText(n)),
+						Attributes: Attributes{
+							ID: id,
+						},
+					}
+					nodeElements = append(nodeElements, ref)
+				} else {
+					transparent = true
+				}
+
+			case "details":
+				// Collapsible section
+				details := Element{
+					Type:     "details",
+					Children: traverseChildren(n),
+				}
+				nodeElements = append(nodeElements, details)
+
+			case "summary":
+				// Collapsible section title
+				summary := Element{
+					Type:     "summary",
+					Content:  extractAllText(n),
+					Children: traverseChildren(n),
+				}
+				nodeElements = append(nodeElements, summary)
+
 			case "table":
 				// Table
 				table := Element{
 					Type:     "table",
-					Children: traverse(n.FirstChild),
+					Children: traverseChildren(n),
 				}
 				nodeElements = append(nodeElements, table)
 
@@ -200,28 +525,51 @@ func parseHTMLToElements(htmlContent string) []Element {
 				// Table row
 				row := Element{
 					Type:     "table_row",
-					Children: traverse(n.FirstChild),
+					Children: traverseChildren(n),
 				}
 				nodeElements = append(nodeElements, row)
 
 			case "th":
 				// Table header cell
 				headerCell := Element{
-					Type:     "table_header_cell",
-					Content:  extractNodeText(n),
-					Children: traverse(n.FirstChild),
+					Type:       "table_header_cell",
+					Content:    extractNodeText(n),
+					Children:   traverseChildren(n),
+					Attributes: Attributes{Align: cellAlign(n)},
 				}
 				nodeElements = append(nodeElements, headerCell)
 
 			case "td":
 				// Table cell
 				cell := Element{
-					Type:     "table_cell",
-					Content:  extractNodeText(n),
-					Children: traverse(n.FirstChild),
+					Type:       "table_cell",
+					Content:    extractNodeText(n),
+					Children:   traverseChildren(n),
+					Attributes: Attributes{Align: cellAlign(n)},
 				}
 				nodeElements = append(nodeElements, cell)
 
+			default:
+				transparent = true
+			}
+
+			// Elements without a type of their own contribute their children,
+			// wrapped in a container when they carry data-* configuration
+			if transparent {
+				if data := getDataAttrs(n); data != nil {
+					container := Element{
+						Type:       containerType(n),
+						Children:   traverseChildren(n),
+						Attributes: Attributes{Data: data},
+					}
+					return []Element{container}
+				}
+				return traverseChildren(n)
+			}
+
+			// Keep custom component configuration from data-* attributes
+			if len(nodeElements) > 0 {
+				nodeElements[0].Attributes.Data = getDataAttrs(n)
 			}
 
 		case html.TextNode:
@@ -234,25 +582,77 @@ func parseHTMLToElements(htmlContent string) []Element {
 				nodeElements = append(nodeElements, text)
 			}
 
+		case html.DocumentNode:
+			// Document root, when there is no body to start from
+			return traverseChildren(n)
+
 		default:
 			// Handle any unmatched element types
-			log.Printf("Unhandled element type: %s", n.Data)
-		}
-
-		// Traverse siblings
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			nodeElements = append(nodeElements, traverse(c)...)
+			logf(ctx, "Unhandled element type: %s", n.Data)
 		}
 
 		return nodeElements
 	}
 
-	// Start traversing from the root
-	elements = traverse(doc)
+	// Start traversing from the body so the html/head wrappers are skipped
+	if body := findBodyNode(doc); body != nil {
+		elements = traverse(body)
+	} else {
+		elements = traverse(doc)
+	}
 
 	return elements
 }
 
+// Helper function to drop text elements left empty after stripping a marker
+func dropEmptyText(elements []Element) []Element {
+	kept := elements[:0]
+	for _, el := range elements {
+		if el.Type == "text" && el.Content == "" && len(el.Children) == 0 {
+			continue
+		}
+		if el.Type == "paragraph" {
+			el.Children = dropEmptyText(el.Children)
+		}
+		kept = append(kept, el)
+	}
+	return kept
+}
+
+// Helper function to drop the line break the markdown renderer leaves at the end of an item
+func trimTrailingLineBreak(elements []Element) []Element {
+	if last := len(elements) - 1; last >= 0 && elements[last].Type == "line_break" {
+		return elements[:last]
+	}
+	return elements
+}
+
+// Helper function to drop the line breaks the markdown renderer leaves at the
+// end of list item text, before a nested block or the end of the item
+func trimItemLineBreaks(elements []Element) []Element {
+	kept := elements[:0]
+	for i, el := range elements {
+		if el.Type == "line_break" && (i == len(elements)-1 || !inlineElementTypes[elements[i+1].Type]) {
+			continue
+		}
+		kept = append(kept, el)
+	}
+	return kept
+}
+
+// Helper function to find the <body> element of a parsed document
+func findBodyNode(n *html.Node) *html.Node {
+	if n.Type == html.ElementNode && n.Data == "body" {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if body := findBodyNode(c); body != nil {
+			return body
+		}
+	}
+	return nil
+}
+
 // Helper function to extract text from HTML node
 func extractNodeText(n *html.Node) string {
 	var text string
@@ -264,6 +664,66 @@ func extractNodeText(n *html.Node) string {
 	return strings.TrimSpace(text)
 }
 
+// Helper function to extract text from a node and all its descendants
+func extractAllText(n *html.Node) string {
+	return strings.TrimSpace(extractRawText(n))
+}
+
+// Helper function to extract all descendant text exactly as written
+func extractRawText(n *html.Node) string {
+	var sb strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.TextNode {
+				sb.WriteString(c.Data)
+			}
+			collect(c)
+		}
+	}
+	collect(n)
+	return sb.String()
+}
+
+// Helper function to read the language of a code block from the class of
+// its <code> child, e.g. class="language-go"
+func codeBlockLanguage(pre *html.Node) string {
+	for c := pre.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.Data != "code" {
+			continue
+		}
+		for _, class := range strings.Fields(getAttr(c, "class")) {
+			if language, ok := strings.CutPrefix(class, "language-"); ok {
+				return language
+			}
+		}
+	}
+	return ""
+}
+
+// Helper function to collect data-* attributes keyed without the prefix
+func getDataAttrs(n *html.Node) map[string]string {
+	var data map[string]string
+	for _, a := range n.Attr {
+		if name, ok := strings.CutPrefix(a.Key, "data-"); ok && name != "" {
+			if data == nil {
+				data = make(map[string]string)
+			}
+			data[name] = a.Val
+		}
+	}
+	return data
+}
+
+// Helper function to name the container kept for a transparent element,
+// spans stay inline while anything else wraps blocks
+func containerType(n *html.Node) string {
+	if n.Data == "span" {
+		return "span"
+	}
+	return "container"
+}
+
 // Helper function to get attribute value
 func getAttr(n *html.Node, attr string) string {
 	for _, a := range n.Attr {
@@ -274,37 +734,87 @@ func getAttr(n *html.Node, attr string) string {
 	return ""
 }
 
+// Helper function to read a table cell's alignment from its align attribute
+// or a text-align style
+func cellAlign(n *html.Node) string {
+	align := strings.ToLower(strings.TrimSpace(getAttr(n, "align")))
+	if align == "" {
+		for _, decl := range strings.Split(getAttr(n, "style"), ";") {
+			property, value, ok := strings.Cut(decl, ":")
+			if ok && strings.EqualFold(strings.TrimSpace(property), "text-align") {
+				align = strings.ToLower(strings.TrimSpace(value))
+			}
+		}
+	}
+
+	switch align {
+	case "left", "center", "right":
+		return align
+	}
+	return ""
+}
+
 // Updated GitHub API interaction functions with improved error handling
-func getReadmeContent(ctx context.Context, owner, repo string) (string, error) {
-	token := os.Getenv("GITHUB_TOKEN")
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/readme", owner, repo)
+func getReadmeContent(ctx context.Context, owner, repo, ref string) (string, error) {
+	resp, body, err := doConditionalGitHubRequest(ctx, readmeAPIURL(owner, repo, ref), githubJSONMediaType)
+	if err != nil {
+		return "", err
+	}
+	recordDebugResponse(ctx, "readme", body)
+	if err := checkGitHubResponse(resp, body); err != nil {
+		return "", err
+	}
+	return decodeReadmeResponse(body)
+}
+
+// Fetch and parse a README. When GitHub reports the README unchanged the
+// document parsed from the remembered response is reused without parsing.
+func getParsedReadme(ctx context.Context, owner, repo, ref string, opts readmeOptions) (MarkdownDocument, FrontMatter, error) {
+	url := readmeAPIURL(owner, repo, ref)
+	key := "readme|" + url + "|" + parseOptionsKey(opts)
+
+	// Debug requests capture the raw response, so always fetch it whole
+	var cached etagEntry
+	var hasCached bool
+	if !opts.DebugRaw {
+		cached, hasCached = githubETags.get(key)
+	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	resp, body, err := doGitHubRequestWithETag(ctx, url, githubJSONMediaType, cached.etag)
 	if err != nil {
-		return "", fmt.Errorf("creating request: %w", err)
+		return MarkdownDocument{}, FrontMatter{}, err
+	}
+	if resp.StatusCode == http.StatusNotModified && hasCached {
+		return cached.doc, cached.frontMatter, nil
+	}
+	recordDebugResponse(ctx, "readme", body)
+	if err := checkGitHubResponse(resp, body); err != nil {
+		return MarkdownDocument{}, FrontMatter{}, err
 	}
-	req.Header.Set("Authorization", "token "+token)
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	content, err := decodeReadmeResponse(body)
 	if err != nil {
-		return "", fmt.Errorf("making request: %w", err)
+		return MarkdownDocument{}, FrontMatter{}, err
 	}
+	doc, fm := parseDocument(ctx, content, opts)
 
-	// Improved response body closure with error handling
-	defer func() {
-		closeErr := resp.Body.Close()
-		if closeErr != nil {
-			log.Printf("Error closing response body: %v", closeErr)
-		}
-	}()
+	if etag := resp.Header.Get("ETag"); etag != "" && !opts.DebugRaw {
+		githubETags.set(key, etagEntry{etag: etag, doc: doc, frontMatter: fm})
+	}
+	return doc, fm, nil
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("reading response: %w", err)
+// Helper function to build the API URL of a repository README
+func readmeAPIURL(owner, repo, ref string) string {
+	url := githubAPIURL("/repos/%s/%s/readme", neturl.PathEscape(owner), neturl.PathEscape(repo))
+	if ref != "" {
+		url += "?ref=" + neturl.QueryEscape(ref)
 	}
+	return url
+}
 
+// Helper function to decode README content from a contents API response
+func decodeReadmeResponse(body []byte) (string, error) {
 	var readmeResp struct {
 		Content  string `json:"content"`
 		Encoding string `json:"encoding"`
@@ -313,50 +823,44 @@ func getReadmeContent(ctx context.Context, owner, repo string) (string, error) {
 		return "", fmt.Errorf("parsing response: %w", err)
 	}
 
-	decodedContent, err := base64.StdEncoding.DecodeString(
-		strings.ReplaceAll(readmeResp.Content, "\n", ""),
-	)
+	return decodeContent(readmeResp.Content)
+}
+
+// Helper function to decode base64 content returned by the contents API
+func decodeContent(encoded string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(encoded, "\n", ""))
 	if err != nil {
 		return "", fmt.Errorf("decoding content: %w", err)
 	}
-
-	return string(decodedContent), nil
+	if len(decoded) > maxContentBytes {
+		return "", errContentTooLarge
+	}
+	return string(decoded), nil
 }
 
 func getRepositoryMetadata(ctx context.Context, owner, repo string) (DocumentMetadata, error) {
-	token := os.Getenv("GITHUB_TOKEN")
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo)
+	url := githubAPIURL("/repos/%s/%s", neturl.PathEscape(owner), neturl.PathEscape(repo))
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	resp, body, err := doConditionalGitHubRequest(ctx, url, githubJSONMediaType)
 	if err != nil {
-		return DocumentMetadata{}, fmt.Errorf("creating request: %w", err)
+		return DocumentMetadata{}, err
 	}
-	req.Header.Set("Authorization", "token "+token)
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return DocumentMetadata{}, fmt.Errorf("making request: %w", err)
-	}
-	// Improved response body closure with error handling
-	defer func() {
-		closeErr := resp.Body.Close()
-		if closeErr != nil {
-			log.Printf("Error closing response body: %v", closeErr)
-		}
-	}()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return DocumentMetadata{}, fmt.Errorf("reading response: %w", err)
+	recordDebugResponse(ctx, "repository", body)
+	if err := checkGitHubResponse(resp, body); err != nil {
+		return DocumentMetadata{}, err
 	}
 
 	var repoResp struct {
-		Name        string    `json:"name"`
-		Description string    `json:"description"`
-		UpdatedAt   time.Time `json:"updated_at"`
-		Owner       struct {
+		Name          string    `json:"name"`
+		HTMLURL       string    `json:"html_url"`
+		DefaultBranch string    `json:"default_branch"`
+		Description   string    `json:"description"`
+		Language      string    `json:"language"`
+		Topics        []string  `json:"topics"`
+		Archived      bool      `json:"archived"`
+		Disabled      bool      `json:"disabled"`
+		UpdatedAt     time.Time `json:"updated_at"`
+		Owner         struct {
 			Login string `json:"login"`
 		} `json:"owner"`
 	}
@@ -365,14 +869,18 @@ func getRepositoryMetadata(ctx context.Context, owner, repo string) (DocumentMet
 	}
 
 	// Extract first line from README as title
-	loc, _ := time.LoadLocation("Asia/Kolkata")
-
 	return DocumentMetadata{
 		Title:       extractFirstLineFromReadme(repoResp.Name, repoResp.Description),
 		Repository:  fmt.Sprintf("%s/%s", owner, repo),
-		LastUpdated: repoResp.UpdatedAt.In(loc),
+		URL:         repoResp.HTMLURL,
+		Ref:         repoResp.DefaultBranch,
+		LastUpdated: repoResp.UpdatedAt.In(displayLocation),
 		Author:      repoResp.Owner.Login,
 		Description: repoResp.Description,
+		Language:    repoResp.Language,
+		Topics:      repoResp.Topics,
+		Archived:    repoResp.Archived,
+		Disabled:    repoResp.Disabled,
 	}, nil
 }
 
@@ -389,9 +897,16 @@ func extractFirstLineFromReadme(repoName, description string) string {
 
 // HTTP Handler for README Processing
 func handleReadmeRequest(w http.ResponseWriter, r *http.Request) {
+	serveDocument(w, r, "README", processReadme)
+}
+
+// Fetches and parses a document for the given repository
+type documentSource func(ctx context.Context, owner, repo string, opts readmeOptions) (MarkdownDocument, error)
+
+// Shared handler logic for endpoints that serve a parsed document
+func serveDocument(w http.ResponseWriter, r *http.Request, name string, source documentSource) {
 	// Set CORS headers
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET")
+	setCORSHeaders(w, r)
 	w.Header().Set("Content-Type", "application/json")
 
 	// Handle preflight requests
@@ -400,58 +915,468 @@ func handleReadmeRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	start := time.Now()
+	envelope := r.URL.Query().Get("envelope") == "true"
+
+	// Respond with errors in the same shape as successful responses
+	writeError := func(msg string, status int) {
+		if envelope {
+			writeEnvelope(w, status, ResponseEnvelope{Error: msg, Meta: newResponseMeta(start, false)})
+			return
+		}
+		writeJSONError(w, status, errorCodeForStatus(status), msg)
+	}
+
 	// Extract query parameters
 	owner := r.URL.Query().Get("owner")
 	repo := r.URL.Query().Get("repo")
 
 	if owner == "" || repo == "" {
-		http.Error(w, "Owner and repository are required", http.StatusBadRequest)
+		writeError("Owner and repository are required", http.StatusBadRequest)
+		return
+	}
+	if err := validateRepository(owner, repo); err != nil {
+		writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	opts, err := parseReadmeOptions(r.URL.Query())
+	if err != nil {
+		writeError(err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Process README
-	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	// Process document
+	ctx, cancel := context.WithTimeout(withRequestToken(r.Context(), r), 30*time.Second)
 	defer cancel()
 
-	doc, err := processReadme(ctx, owner, repo)
+	// Capture raw upstream responses for debugraw
+	var capture *debugCapture
+	if opts.DebugRaw {
+		ctx, capture = withDebugCapture(ctx)
+	}
+
+	doc, err := source(ctx, owner, repo, opts)
 	if err != nil {
-		log.Printf("Error processing README: %v", err)
-		http.Error(w, "Failed to process README", http.StatusInternalServerError)
+		if errors.Is(err, errReleaseNotFound) {
+			writeError("Release not found", http.StatusNotFound)
+			return
+		}
+		logf(ctx, "Error processing %s: %v", name, err)
+		status, msg := upstreamErrorStatus(err, name)
+		setRetryAfter(w, err)
+		writeError(msg, status)
+		return
+	}
+
+	if capture != nil {
+		// HTML-only and non-markdown documents are built without debug info
+		if doc.Debug == nil {
+			doc.Debug = &DebugInfo{}
+		}
+		doc.Debug.GitHubResponses = capture.snapshot()
+	}
+
+	w.Header().Set("X-Content-Hash", doc.ContentHash)
+
+	// HTML comes from the markdown source rather than the element tree
+	if opts.Format == formatHTML {
+		rendered := doc.renderedHTML
+		if rendered == "" {
+			rendered = renderContentHTML(ctx, doc.RawContent, true, opts.Markdown)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		writeCacheable(w, r, []byte(rendered), hasRequestToken(ctx))
 		return
 	}
 
+	doc = applyDocumentOptions(doc, owner, repo, opts)
+
+	// Serve non-JSON renderings of the element tree
+	var rendered, contentType string
+	switch opts.Format {
+	case formatDOT:
+		rendered, contentType = RenderDOT(doc.Content), "text/vnd.graphviz; charset=utf-8"
+	case formatMarkdown:
+		rendered, contentType = RenderMarkdown(doc.Content), "text/markdown; charset=utf-8"
+	case formatText:
+		rendered, contentType = RenderPlainText(doc.Content), "text/plain; charset=utf-8"
+	}
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+		writeCacheable(w, r, []byte(rendered), hasRequestToken(ctx))
+		return
+	}
+
+	// Wrap the document with response metadata when requested, and remap
+	// JSON keys and element types to the requested convention
+	encode := func(meta ResponseMeta) ([]byte, error) {
+		var response any = doc
+		if envelope {
+			response = ResponseEnvelope{Data: doc, Meta: meta}
+		}
+		if opts.Casing != "" {
+			var err error
+			response, err = applyCasing(response, opts.Casing)
+			if err != nil {
+				return nil, fmt.Errorf("applying casing: %w", err)
+			}
+		}
+
+		var body bytes.Buffer
+		if err := json.NewEncoder(&body).Encode(response); err != nil {
+			return nil, fmt.Errorf("encoding response: %w", err)
+		}
+		return body.Bytes(), nil
+	}
+
 	// Encode and send response
-	if err := json.NewEncoder(w).Encode(doc); err != nil {
-		log.Printf("Error encoding response: %v", err)
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	body, err := encode(newResponseMeta(start, doc.fromCache))
+	if err != nil {
+		logf(ctx, "Error %v", err)
+		writeError("Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	// Timing and cache status differ per request, so the ETag of an
+	// envelope covers the document alone
+	tagged := body
+	if envelope {
+		tagged, err = encode(ResponseMeta{SchemaVersion: schemaVersion})
+		if err != nil {
+			logf(ctx, "Error %v", err)
+			writeError("Failed to encode response", http.StatusInternalServerError)
+			return
+		}
 	}
+	writeTaggedCacheable(w, r, body, tagged, hasRequestToken(ctx))
+}
+
+// Run the optional transformations requested by opts over a parsed document
+func applyDocumentOptions(doc MarkdownDocument, owner, repo string, opts readmeOptions) MarkdownDocument {
+	// Remove invisible characters before anything reads the text
+	if opts.CleanText {
+		applyCleanText(doc.Content)
+	}
+
+	// Turn GitHub shortcodes such as :rocket: into emoji
+	if opts.Emoji {
+		applyEmoji(doc.Content)
+	}
+
+	// Derive alt text for images that have none
+	if opts.AltFallback {
+		applyAltFallback(doc.Content)
+	}
+
+	// Build the table of contents before any slicing of the content
+	doc.TableOfContents = buildTableOfContents(doc.Content, opts.TOCDepth, opts.TOCStyle)
+
+	// Report authoring mistakes such as dangling reference links
+	if opts.Validate {
+		doc.Warnings = validateDocument(doc.Content)
+	}
+
+	// Repair in-page links whose slug does not quite match a heading
+	if opts.FixAnchors {
+		doc.AnchorFixes = fixAnchorLinks(doc.Content)
+	}
+
+	// Infer topics for catalog tagging
+	if opts.Topics {
+		doc.DetectedTopics = DetectTopics(doc)
+	}
+
+	// Flatten descendant text onto containers for search indexing
+	if opts.TextContent {
+		applyTextContent(doc.Content)
+	}
+
+	// List the dependencies of code examples
+	if opts.Imports {
+		applyCodeImports(doc.Content)
+	}
+
+	// Collect content images with absolute sources for galleries
+	if opts.Images {
+		doc.Images = ExtractImages(doc.Content)
+		for i := range doc.Images {
+			doc.Images[i].Src = rawFileURL(owner, repo, requestRef(owner, repo, opts), doc.Images[i].Src)
+		}
+	}
+
+	// Heading ID lookup for client-side link validation
+	if opts.Anchors {
+		doc.Anchors = collectAnchors(doc.Content)
+	}
+
+	// Group headings with their code examples into an API reference
+	if opts.APIRef {
+		doc.APIReference = buildAPIReference(doc.Content)
+	}
+
+	// Link each heading to its anchor on GitHub
+	if opts.Permalinks && doc.pageURL != "" {
+		applyPermalinks(doc.Content, doc.pageURL)
+	}
+
+	// Shorten long content for previews
+	if opts.Truncate > 0 {
+		applyTruncation(doc.Content, opts.Truncate)
+	}
+
+	// Slice the top-level elements when pagination was requested
+	if opts.Paginate {
+		doc = paginateDocument(doc, opts.Offset, opts.Limit)
+	}
+
+	// Return each rule-delimited part as its own sub-document
+	if opts.SplitOnHR {
+		doc.Documents = splitOnHorizontalRules(doc.Content)
+		doc.Content = nil
+	}
+
+	return doc
 }
 
 // Process README
-func processReadme(ctx context.Context, owner, repo string) (MarkdownDocument, error) {
-	// Fetch README content
-	readmeContent, err := getReadmeContent(ctx, owner, repo)
+func processReadme(ctx context.Context, owner, repo string, opts readmeOptions) (MarkdownDocument, error) {
+	// Comparisons span two refs and are not cached
+	if opts.CompareBase != "" {
+		doc, err := compareReadme(ctx, owner, repo, opts)
+		if err != nil {
+			return MarkdownDocument{}, err
+		}
+		rewriteRelativeURLs(doc.Content, owner, repo, doc.Metadata.Ref, opts.Path)
+		doc.pageURL = readmePageURL(doc.Metadata.URL, owner, repo, opts)
+		return doc, nil
+	}
+
+	// Debug requests always go upstream so the raw responses are captured.
+	// In content mode the fetch always happens and only parsing is cached.
+	// Documents fetched with a caller's own token may be private and are
+	// never shared through the cache.
+	useCache := cacheMode == cacheModeRepository && !opts.DebugRaw && !hasRequestToken(ctx)
+	cacheKey := readmeCacheKey(owner, repo, opts)
+	if useCache {
+		if doc, ok := readmeCache.get(cacheKey); ok {
+			doc.fromCache = true
+			return doc, nil
+		}
+	}
+
+	doc, err := fetchReadmeDocument(ctx, owner, repo, opts)
 	if err != nil {
-		return MarkdownDocument{}, fmt.Errorf("fetching readme: %w", err)
+		return MarkdownDocument{}, err
+	}
+	if ref := requestRef(owner, repo, opts); ref != "" {
+		doc.Metadata.Ref = ref
+	}
+	rewriteRelativeURLs(doc.Content, owner, repo, doc.Metadata.Ref, opts.Path)
+	doc.pageURL = readmePageURL(doc.Metadata.URL, owner, repo, opts)
+
+	if useCache {
+		readmeCache.set(cacheKey, doc)
+	}
+	return doc, nil
+}
+
+// Fetch and parse a README, or the requested file, from GitHub
+func fetchReadmeDocument(ctx context.Context, owner, repo string, opts readmeOptions) (MarkdownDocument, error) {
+	// Serve a specific file instead of the README when a path is given
+	if opts.Path != "" {
+		return processFile(ctx, owner, repo, opts.Path, opts)
+	}
+
+	// Serve the README as of a release, enriched with the release details
+	if opts.Release != "" {
+		return processReleaseReadme(ctx, owner, repo, opts)
 	}
 
+	// Fetch and parse the README alongside the repository metadata
+	var doc MarkdownDocument
+	var fm FrontMatter
+	_, metadata, err := fetchWithMetadata(ctx, owner, repo, func(ctx context.Context) (string, error) {
+		var err error
+		doc, fm, err = getParsedReadme(ctx, owner, repo, requestRef(owner, repo, opts), opts)
+		if err != nil {
+			return "", fmt.Errorf("fetching readme: %w", err)
+		}
+		return "", nil
+	})
+	if err != nil {
+		return MarkdownDocument{}, err
+	}
+
+	return withRepositoryMetadata(doc, fm, metadata), nil
+}
+
+// Fetch document content and repository metadata in parallel. The first
+// error cancels the other request.
+func fetchWithMetadata(ctx context.Context, owner, repo string, fetch func(context.Context) (string, error)) (string, DocumentMetadata, error) {
+	g, gctx := errgroup.WithContext(ctx)
+
+	var content string
+	g.Go(func() error {
+		var err error
+		content, err = fetch(gctx)
+		return err
+	})
+
+	var metadata DocumentMetadata
+	g.Go(func() error {
+		var err error
+		metadata, err = getRepositoryMetadata(gctx, owner, repo)
+		if err != nil {
+			return fmt.Errorf("fetching metadata: %w", err)
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return "", DocumentMetadata{}, err
+	}
+	return content, metadata, nil
+}
+
+// Run markdown content through the parsing pipeline
+func renderDocument(ctx context.Context, content string, metadata DocumentMetadata, opts readmeOptions) MarkdownDocument {
+	doc, fm := parseDocument(ctx, content, opts)
+	return withRepositoryMetadata(doc, fm, metadata)
+}
+
+// Helper function to combine a parsed document with repository metadata.
+// Front matter overrides the repository values.
+func withRepositoryMetadata(doc MarkdownDocument, fm FrontMatter, metadata DocumentMetadata) MarkdownDocument {
+	applyFrontMatter(&metadata, fm)
+	metadata.NoIndex = doc.Metadata.NoIndex
+	metadata.TaskProgress = doc.Metadata.TaskProgress
+	metadata.WordCount = doc.Metadata.WordCount
+	metadata.ReadingTimeMinutes = doc.Metadata.ReadingTimeMinutes
+	doc.Metadata = metadata
+	return doc
+}
+
+// Parse markdown content into a document carrying only the metadata derived
+// from the content itself, along with any front matter
+func parseDocument(ctx context.Context, content string, opts readmeOptions) (MarkdownDocument, FrontMatter) {
+	// Hash the raw content so clients can detect changes cheaply
+	hash := sha256.Sum256([]byte(content))
+	contentHash := hex.EncodeToString(hash[:])
+
+	var metadata DocumentMetadata
+
+	// Respect an author's request not to be indexed, which may sit in front matter
+	metadata.NoIndex = detectNoIndex(content)
+
+	// Front matter feeds the metadata rather than the body
+	frontMatterLines := 0
+	fm, body, ok := splitFrontMatter(content)
+	if ok {
+		frontMatterLines = strings.Count(content[:len(content)-len(body)], "\n")
+		content = body
+	}
+
+	// HTML responses are rendered straight from the markdown, so the element
+	// tree is never built. Served as a page from this origin, raw HTML is
+	// always sanitized.
+	if opts.Format == formatHTML {
+		return MarkdownDocument{
+			Metadata:     metadata,
+			RawContent:   content,
+			ContentHash:  contentHash,
+			renderedHTML: renderContentHTML(ctx, content, true, opts.Markdown),
+		}, fm
+	}
+
+	// In content mode identical content shares one parse across refs and repos
+	useContentCache := cacheMode == cacheModeContent && !opts.DebugRaw
+	contentKey := contentCacheKey(contentHash, opts)
+
+	var cached MarkdownDocument
+	var hit bool
+	if useContentCache {
+		cached, hit = contentCache.get(contentKey)
+	}
+
+	var parsedContent []Element
+	var htmlContent string
+	if hit {
+		parsedContent = cached.Content
+	} else {
+		parsedContent, htmlContent = parseContent(ctx, content, opts)
+		if useContentCache {
+			contentCache.set(contentKey, MarkdownDocument{Content: parsedContent})
+		}
+	}
+
+	// Positions count lines of the original source, front matter included
+	if opts.Positions && frontMatterLines > 0 {
+		for i := range parsedContent {
+			if parsedContent[i].Line > 0 {
+				parsedContent[i].Line += frontMatterLines
+			}
+		}
+	}
+
+	// Summarize checklist completion
+	metadata.TaskProgress = computeTaskProgress(parsedContent)
+
+	// Estimate reading time from visible text
+	metadata.WordCount = countWords(parsedContent)
+	metadata.ReadingTimeMinutes = readingTimeMinutes(metadata.WordCount)
+
+	// Footnote definitions are listed apart from the body
+	parsedContent, footnotes := extractFootnotes(parsedContent)
+
+	doc := MarkdownDocument{
+		Metadata:    metadata,
+		Content:     parsedContent,
+		RawContent:  content,
+		ContentHash: contentHash,
+		Footnotes:   footnotes,
+	}
+
+	// Keep the intermediate HTML for debugging
+	if opts.DebugRaw {
+		doc.Debug = &DebugInfo{HTML: htmlContent}
+	}
+
+	return doc, fm
+}
+
+// Render markdown to HTML, optionally stripping raw HTML the sanitizer policy does not allow
+func renderContentHTML(ctx context.Context, content string, sanitize bool, md markdownOptions) string {
 	// Convert Markdown to HTML
-	htmlContent := parseMarkdownToHTML([]byte(readmeContent))
+	htmlContent := expandDetailsMarkdown(ctx, parseMarkdownToHTML([]byte(content), md), md)
+
+	if sanitize {
+		htmlContent = sanitizeHTML(ctx, htmlContent, sanitizePolicy)
+	}
+	return htmlContent
+}
+
+// Parse markdown into structured elements, also returning the intermediate HTML
+func parseContent(ctx context.Context, content string, opts readmeOptions) ([]Element, string) {
+	htmlContent := renderContentHTML(ctx, content, opts.Sanitize, opts.Markdown)
 
 	// Parse HTML to structured elements
-	parsedContent := parseHTMLToElements(htmlContent)
+	parsedContent := parseHTMLToElements(ctx, htmlContent)
 
-	// Get repository metadata
-	metadata, err := getRepositoryMetadata(ctx, owner, repo)
-	if err != nil {
-		return MarkdownDocument{}, fmt.Errorf("fetching metadata: %w", err)
+	// Carry fence annotations the HTML renderer drops
+	applyHighlightLines(parsedContent, []byte(content), opts.Markdown)
+
+	// Map top-level elements back to their markdown source
+	if opts.SourceMarkdown {
+		applySourceMarkdown(parsedContent, content, opts.Markdown)
 	}
 
-	return MarkdownDocument{
-		Metadata:   metadata,
-		Content:    parsedContent,
-		RawContent: readmeContent,
-	}, nil
+	// Record where top-level elements start for editor sync
+	if opts.Positions {
+		applySourcePositions(parsedContent, content, opts.Markdown)
+	}
+
+	return parsedContent, htmlContent
 }
 
 func main() {
@@ -460,8 +1385,93 @@ func main() {
 		log.Fatal("GITHUB_TOKEN environment variable is not set")
 	}
 
+	// Configure the document cache
+	cacheTTL, err := cacheTTLFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	cacheMaxEntries, err := cacheMaxEntriesFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	readmeCache = newDocumentCache(cacheTTL, cacheMaxEntries)
+	contentCache = newDocumentCache(cacheTTL, cacheMaxEntries)
+
+	// Bound the remembered GitHub responses like the document caches
+	etagTTL, err := etagTTLFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	githubETags = newETagStore(etagTTL, cacheMaxEntries)
+
+	cacheMode, err = cacheModeFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Configure the reading speed for reading time estimates
+	wordsPerMinute, err = positiveIntFromEnv("READING_WPM", defaultWordsPerMinute)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Bound recursion on deeply nested documents
+	maxNestingDepth, err = positiveIntFromEnv("MAX_NESTING_DEPTH", defaultMaxNestingDepth)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Configure how long clients may cache responses
+	responseMaxAge, err = responseMaxAgeFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Restrict cross-origin access when an allow-list is configured
+	allowedOrigins, allowedMethods = corsConfigFromEnv()
+
+	// Cap the size of documents fetched from GitHub
+	maxContentBytes, err = positiveIntFromEnv("MAX_README_BYTES", defaultMaxContentBytes)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Configure batch concurrency limits
+	if err := configureBatchConcurrency(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Point API calls at GitHub Enterprise when configured
+	githubAPIBase, err = githubAPIBaseFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Configure the timezone timestamps are reported in
+	displayLocation, err = displayLocationFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Load per-repository docs branches
+	defaultRefs, err = defaultRefsFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Load a custom sanitizer policy when configured
+	if policyPath := os.Getenv("SANITIZE_POLICY_FILE"); policyPath != "" {
+		policy, err := loadSanitizePolicy(policyPath)
+		if err != nil {
+			log.Fatalf("Error loading sanitizer policy: %v", err)
+		}
+		sanitizePolicy = policy
+	}
+
 	// Configure routes
-	http.HandleFunc("/readme", handleReadmeRequest)
+	for _, rt := range apiRoutes() {
+		http.HandleFunc(rt.Path, withRequestID(rt.Handler))
+	}
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -469,6 +1479,18 @@ func main() {
 		port = "8080"
 	}
 
+	shutdownTimeout, err := shutdownTimeoutFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Stop gracefully on SIGINT/SIGTERM, e.g. a container stop
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{Addr: ":" + port}
 	log.Printf("Server starting on :%s", port)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+	if err := runServer(ctx, srv, shutdownTimeout); err != nil {
+		log.Fatal(err)
+	}
 }
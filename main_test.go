@@ -0,0 +1,559 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// Helper function to parse request options from a query string
+func testOptions(t *testing.T, rawQuery string) readmeOptions {
+	t.Helper()
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		t.Fatalf("parsing query %q: %v", rawQuery, err)
+	}
+	opts, err := parseReadmeOptions(query)
+	if err != nil {
+		t.Fatalf("parseReadmeOptions(%q): %v", rawQuery, err)
+	}
+	return opts
+}
+
+// Helper function to parse markdown with the default request options
+func parseTestMarkdown(t *testing.T, markdownContent string) []Element {
+	t.Helper()
+	return renderDocument(context.Background(), markdownContent, DocumentMetadata{}, testOptions(t, "")).Content
+}
+
+// Helper function to parse markdown into a document with the options of a query string
+func parseTestMarkdownDoc(t *testing.T, markdownContent, rawQuery string) MarkdownDocument {
+	t.Helper()
+	return renderDocument(context.Background(), markdownContent, DocumentMetadata{}, testOptions(t, rawQuery))
+}
+
+// Helper function to find the first element of a type, depth first
+func findElement(elements []Element, elementType string) *Element {
+	for i := range elements {
+		if elements[i].Type == elementType {
+			return &elements[i]
+		}
+		if found := findElement(elements[i].Children, elementType); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// Helper function to collect every element of a type, depth first
+func findElements(elements []Element, elementType string) []Element {
+	var found []Element
+	for _, el := range elements {
+		if el.Type == elementType {
+			found = append(found, el)
+		}
+		found = append(found, findElements(el.Children, elementType)...)
+	}
+	return found
+}
+
+// Helper function to point GitHub API calls at a test server for one test
+func stubGitHub(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	previous := githubAPIBase
+	githubAPIBase = srv.URL
+	t.Cleanup(func() { githubAPIBase = previous })
+	t.Setenv("GITHUB_TOKEN", "test-token")
+
+	// Start from empty caches so no document leaks between tests
+	previousReadmes, previousContent, previousETags := readmeCache, contentCache, githubETags
+	readmeCache = newDocumentCache(defaultCacheTTL, defaultCacheMaxEntries)
+	contentCache = newDocumentCache(defaultCacheTTL, defaultCacheMaxEntries)
+	githubETags = newETagStore(defaultETagTTL, defaultCacheMaxEntries)
+	t.Cleanup(func() {
+		readmeCache, contentCache, githubETags = previousReadmes, previousContent, previousETags
+	})
+	return srv
+}
+
+// Helper function to serve markdown READMEs for the given repositories,
+// keyed by "owner/repo". Other repositories are not found.
+func stubReadmes(t *testing.T, readmes map[string]string) *httptest.Server {
+	t.Helper()
+	return stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		fullName, isReadme := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/repos/"), "/readme")
+		content, ok := readmes[fullName]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+			return
+		}
+		if isReadme {
+			fmt.Fprintf(w, `{"content":%q,"encoding":"base64"}`, base64.StdEncoding.EncodeToString([]byte(content)))
+			return
+		}
+		owner, repo, _ := strings.Cut(fullName, "/")
+		fmt.Fprintf(w, `{"name":%q,"html_url":"https://github.com/%s","default_branch":"main","owner":{"login":%q}}`, repo, fullName, owner)
+	})
+}
+
+func TestDataAttributesAreCaptured(t *testing.T) {
+	doc := renderDocument(context.Background(), "<img src=\"chart.png\" data-chart=\"bar\" data-height=\"300\">\n", DocumentMetadata{}, testOptions(t, "sanitize=false"))
+
+	image := findElement(doc.Content, "image")
+	if image == nil {
+		t.Fatalf("no image in %+v", doc.Content)
+	}
+	if image.Attributes.Data["chart"] != "bar" || image.Attributes.Data["height"] != "300" || len(image.Attributes.Data) != 2 {
+		t.Errorf("image data = %v, want chart=bar and height=300", image.Attributes.Data)
+	}
+}
+
+func TestTransparentElementsKeepDataAttributes(t *testing.T) {
+	markdownContent := "<div data-component=\"chart\">\n\nBody\n\n</div>\n\nBuild <span data-status=\"passing\">ok</span> today\n"
+	doc := renderDocument(context.Background(), markdownContent, DocumentMetadata{}, testOptions(t, "sanitize=false"))
+
+	container := findElement(doc.Content, "container")
+	if container == nil || container.Attributes.Data["component"] != "chart" {
+		t.Fatalf("div data-* was not kept on a container: %+v", doc.Content)
+	}
+	if len(container.Children) == 0 {
+		t.Error("container lost the div's children")
+	}
+
+	span := findElement(doc.Content, "span")
+	if span == nil || span.Attributes.Data["status"] != "passing" {
+		t.Fatalf("span data-* was not kept: %+v", doc.Content)
+	}
+	if got := RenderPlainText(doc.Content); !strings.Contains(got, "Build ok today") {
+		t.Errorf("span did not stay inline, plain text = %q", got)
+	}
+}
+
+func TestTransparentElementsWithoutDataAreFlattened(t *testing.T) {
+	doc := renderDocument(context.Background(), "<div>\n\nBody\n\n</div>\n", DocumentMetadata{}, testOptions(t, "sanitize=false"))
+	if findElement(doc.Content, "container") != nil {
+		t.Errorf("plain div produced a container: %+v", doc.Content)
+	}
+}
+
+func TestCodeBlocksAreVerbatim(t *testing.T) {
+	code := findElement(parseTestMarkdown(t, "```\n    indented first line\nsecond\n\n```\n"), "code_block")
+	if code == nil {
+		t.Fatal("no code block")
+	}
+	if want := "    indented first line\nsecond\n"; code.Content != want {
+		t.Errorf("code = %q, want %q", code.Content, want)
+	}
+}
+
+func TestHorizontalRuleKeepsItsPlace(t *testing.T) {
+	elements := parseTestMarkdown(t, "Before\n\n---\n\nAfter\n")
+
+	var types []string
+	for _, el := range elements {
+		types = append(types, el.Type)
+	}
+	if strings.Join(types, ",") != "paragraph,horizontal_rule,paragraph" {
+		t.Errorf("top-level types = %v", types)
+	}
+	if rule := elements[1]; len(rule.Children) != 0 || rule.Content != "" {
+		t.Errorf("rule = %+v, want no content", rule)
+	}
+}
+
+func TestLineBreaksWithinParagraphs(t *testing.T) {
+	paragraph := findElement(parseTestMarkdown(t, "first\nsecond\n"), "paragraph")
+	if paragraph == nil || len(paragraph.Children) != 3 || paragraph.Children[1].Type != "line_break" {
+		t.Errorf("paragraph = %+v, want text, line_break, text", paragraph)
+	}
+}
+
+func TestListItemsHaveNoTrailingLineBreak(t *testing.T) {
+	elements := parseTestMarkdown(t, "- one\n- [x] two\n- three\n  - nested\n- four  \n  continued\n")
+
+	items := append(findElements(elements, "list_item"), findElements(elements, "task_list_item")...)
+	if len(items) != 5 {
+		t.Fatalf("got %d items, want 5", len(items))
+	}
+	for _, item := range items {
+		for i, child := range item.Children {
+			if child.Type == "line_break" && (i == len(item.Children)-1 || !inlineElementTypes[item.Children[i+1].Type]) {
+				t.Errorf("item %+v keeps a line break before %d", item, i)
+			}
+		}
+	}
+
+	last := items[3]
+	if len(last.Children) != 3 || last.Children[1].Type != "line_break" {
+		t.Errorf("explicit line break inside an item was dropped: %+v", last)
+	}
+}
+
+// Helper function to list the terms and descriptions of a definition
+// list in document order, as "term:text" and "description:text"
+func definitionEntries(list Element) []string {
+	var entries []string
+	for _, term := range list.Children {
+		var text []string
+		var descriptions []string
+		for _, child := range term.Children {
+			if child.Type == "definition_description" {
+				descriptions = append(descriptions, "description:"+plainText(child))
+			} else {
+				text = append(text, plainText(child))
+			}
+		}
+		entries = append(entries, term.Type+":"+strings.Join(text, " "))
+		entries = append(entries, descriptions...)
+	}
+	return entries
+}
+
+func TestDefinitionListKeepsOrderAndFormatting(t *testing.T) {
+	list := findElement(parseTestMarkdown(t, "Apple\n: A fruit\n\n`--flag`\n: Enables it\n: Twice\n"), "definition_list")
+	if list == nil {
+		t.Fatal("no definition list")
+	}
+
+	want := "definition_term:Apple|description:A fruit|definition_term:--flag|description:Enables it|description:Twice"
+	if got := strings.Join(definitionEntries(*list), "|"); got != want {
+		t.Fatalf("entries = %s, want %s", got, want)
+	}
+
+	flag := list.Children[1]
+	if flag.Content != "--flag" || len(flag.Children) != 3 || flag.Children[0].Type != "code" || flag.Children[0].Content != "--flag" {
+		t.Errorf("term lost its inline formatting: %+v", flag)
+	}
+	if got := RenderMarkdown([]Element{*list}); got != "Apple\n: A fruit\n\n`--flag`\n: Enables it\n: Twice\n" {
+		t.Errorf("rendered markdown = %q", got)
+	}
+}
+
+// Helper function to call the README handler
+func getReadme(t *testing.T, query string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/readme?"+query, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	handleReadmeRequest(rec, req)
+	return rec
+}
+
+func TestReadmeFormats(t *testing.T) {
+	stubReadmes(t, map[string]string{"octo/demo": "# Demo\n\nHello <b>there</b>\n"})
+
+	tests := []struct {
+		format      string
+		contentType string
+		body        string
+	}{
+		{"", "application/json", `"type":"heading"`},
+		{"json", "application/json", `"type":"heading"`},
+		{"html", "text/html; charset=utf-8", "<h1 id=\"demo\">Demo</h1>"},
+		{"markdown", "text/markdown; charset=utf-8", "# Demo"},
+		{"text", "text/plain; charset=utf-8", "Hello there"},
+	}
+	for _, tt := range tests {
+		rec := getReadme(t, "owner=octo&repo=demo&format="+tt.format, nil)
+		if rec.Code != http.StatusOK {
+			t.Errorf("format=%s: status %d, body %s", tt.format, rec.Code, rec.Body.String())
+			continue
+		}
+		if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+			t.Errorf("format=%s: Content-Type = %q, want %q", tt.format, got, tt.contentType)
+		}
+		if !strings.Contains(rec.Body.String(), tt.body) {
+			t.Errorf("format=%s: body %q lacks %q", tt.format, rec.Body.String(), tt.body)
+		}
+	}
+
+	if rec := getReadme(t, "owner=octo&repo=demo&format=pdf", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("format=pdf: status %d, want 400", rec.Code)
+	}
+}
+
+func TestHTMLFormatSkipsElementTree(t *testing.T) {
+	doc, _ := parseDocument(context.Background(), "# Demo\n\n<script>x()</script>\n", testOptions(t, "format=html&sanitize=false"))
+	if doc.Content != nil {
+		t.Errorf("format=html built elements: %+v", doc.Content)
+	}
+	if !strings.Contains(doc.renderedHTML, "<h1") || strings.Contains(doc.renderedHTML, "<script") {
+		t.Errorf("rendered HTML = %q, want sanitized markup", doc.renderedHTML)
+	}
+}
+
+func TestGEmojiBecomesText(t *testing.T) {
+	elements := parseHTMLToElements(context.Background(), `<p>Ship it <g-emoji class="g-emoji" alias="rocket">🚀</g-emoji></p><p><g-emoji alias="tada"></g-emoji></p>`)
+
+	texts := findElements(elements, "text")
+	if len(texts) != 3 || texts[1].Content != "🚀" || texts[2].Content != ":tada:" {
+		t.Errorf("texts = %+v, want the emoji and the alias fallback", texts)
+	}
+	if findElement(elements, "g-emoji") != nil {
+		t.Error("g-emoji leaked into the elements")
+	}
+}
+
+func TestDocumentWrapperNodesAreSkipped(t *testing.T) {
+	buf := captureLog(t)
+	elements := parseHTMLToElements(context.Background(), "<h1>Title</h1><p>Body</p>")
+
+	if len(elements) != 2 || elements[0].Type != "heading" || elements[1].Type != "paragraph" {
+		t.Errorf("elements = %+v, want heading and paragraph only", elements)
+	}
+	for _, wrapper := range []string{"html", "head", "body"} {
+		if findElement(elements, wrapper) != nil {
+			t.Errorf("%s wrapper leaked into the elements", wrapper)
+		}
+	}
+	if strings.Contains(buf.String(), "Unhandled element type") {
+		t.Errorf("wrapper nodes were logged as unhandled: %q", buf.String())
+	}
+}
+
+func TestHTMLDescriptionListKeepsEveryDefinition(t *testing.T) {
+	source := "<dl>\n<dt>timeout</dt>\n<dd>Seconds to wait</dd>\n<dd>Zero disables it</dd>\n<dt>retries</dt>\n<dd>Attempts</dd>\n</dl>\n"
+	list := findElement(parseTestMarkdown(t, source), "definition_list")
+	if list == nil {
+		t.Fatal("no definition list")
+	}
+
+	got := definitionEntries(*list)
+	want := []string{
+		"definition_term:timeout",
+		"description:Seconds to wait",
+		"description:Zero disables it",
+		"definition_term:retries",
+		"description:Attempts",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("entries = %q, want %q", got, want)
+	}
+}
+
+func TestInlineCodeInHeadingsAndLinks(t *testing.T) {
+	elements := parseTestMarkdown(t, "## `Config` options\n\nSee [`Run`](https://example.com/run)\n")
+
+	heading := findElement(elements, "heading")
+	if heading == nil || len(heading.Children) != 2 || heading.Children[0].Type != "code" || heading.Children[0].Content != "Config" {
+		t.Errorf("heading = %+v, want a code child", heading)
+	}
+	if heading != nil && heading.Content != "Config options" {
+		t.Errorf("heading content = %q", heading.Content)
+	}
+
+	link := findElement(elements, "link")
+	if link == nil || len(link.Children) != 1 || link.Children[0].Type != "code" || link.Children[0].Content != "Run" {
+		t.Errorf("link = %+v, want a code child", link)
+	}
+}
+
+func TestTimeElementKeepsDatetime(t *testing.T) {
+	elements := parseHTMLToElements(context.Background(), `<p>Released <time datetime="2024-01-01">New Year's Day</time></p>`)
+	el := findElement(elements, "time")
+	if el == nil {
+		t.Fatalf("elements = %+v, want a time element", elements)
+	}
+	if el.Content != "New Year's Day" || el.Attributes.Datetime != "2024-01-01" {
+		t.Errorf("time = %+v", el)
+	}
+}
+
+func TestLinksKeepInlineFormatting(t *testing.T) {
+	link := findElement(parseTestMarkdown(t, "[**bold** and *italic* text](https://example.com)\n"), "link")
+	if link == nil {
+		t.Fatal("no link element")
+	}
+
+	var types []string
+	for _, child := range link.Children {
+		types = append(types, child.Type)
+	}
+	if got := strings.Join(types, ","); got != "strong,text,emphasis,text" {
+		t.Errorf("link children = %s, want strong,text,emphasis,text", got)
+	}
+	if link.Attributes.Href != "https://example.com" {
+		t.Errorf("link href = %q", link.Attributes.Href)
+	}
+	if got := RenderMarkdown([]Element{*link}); got != "[**bold** and *italic* text](https://example.com)\n" {
+		t.Errorf("re-serialized link = %q", got)
+	}
+}
+
+func TestNestedContentIsNotDuplicated(t *testing.T) {
+	elements := parseTestMarkdown(t, "hello [world](https://example.com)\n\n- one\n- two\n")
+	if len(elements) != 2 || elements[0].Type != "paragraph" || elements[1].Type != "unordered_list" {
+		t.Fatalf("top-level elements = %+v, want a paragraph and a list", elements)
+	}
+
+	var texts []string
+	for _, text := range findElements(elements, "text") {
+		texts = append(texts, text.Content)
+	}
+	if got := strings.Join(texts, "|"); got != "hello|world|one|two" {
+		t.Errorf("text elements = %s, want each text once", got)
+	}
+	if got := len(elements[1].Children); got != 2 {
+		t.Errorf("list has %d items, want 2", got)
+	}
+}
+
+func TestCodeBlockLanguage(t *testing.T) {
+	blocks := findElements(parseTestMarkdown(t, "```go\nfmt.Println()\n```\n\n```python\nprint()\n```\n\n```\nplain\n```\n"), "code_block")
+	if len(blocks) != 3 {
+		t.Fatalf("code blocks = %+v, want three", blocks)
+	}
+	for i, want := range []string{"go", "python", ""} {
+		if got := blocks[i].Attributes.Language; got != want {
+			t.Errorf("block %d language = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestHeadingsKeepGeneratedIDs(t *testing.T) {
+	headings := findElements(parseTestMarkdown(t, "## Getting Started\n\n## Getting Started\n\n### API & CLI\n"), "heading")
+	if len(headings) != 3 {
+		t.Fatalf("headings = %+v, want three", headings)
+	}
+	for i, want := range []string{"getting-started", "getting-started-1", "api-cli"} {
+		if got := headings[i].Attributes.ID; got != want {
+			t.Errorf("heading %d ID = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestNestedBlockquotes(t *testing.T) {
+	elements := parseTestMarkdown(t, "> outer\n>\n>> inner\n")
+	if len(elements) != 1 || elements[0].Type != "blockquote" {
+		t.Fatalf("elements = %+v, want one blockquote", elements)
+	}
+
+	outer := elements[0]
+	if len(outer.Children) != 2 || outer.Children[0].Type != "paragraph" || outer.Children[1].Type != "blockquote" {
+		t.Fatalf("outer children = %+v, want a paragraph and a nested quote", outer.Children)
+	}
+	inner := outer.Children[1]
+	if got := RenderPlainText(inner.Children); got != "inner\n" {
+		t.Errorf("inner quote text = %q", got)
+	}
+	if findElement(inner.Children, "blockquote") != nil {
+		t.Errorf("inner quote nests deeper than two levels: %+v", inner)
+	}
+}
+
+func TestStrikethrough(t *testing.T) {
+	paragraphs := findElements(parseTestMarkdown(t, "~~gone~~\n\n~~**bold gone**~~\n\n<s>old</s>\n"), "paragraph")
+	if len(paragraphs) != 3 {
+		t.Fatalf("paragraphs = %+v, want three", paragraphs)
+	}
+
+	plain := findElement(paragraphs[0].Children, "strikethrough")
+	if plain == nil || RenderPlainText(plain.Children) != "gone\n" {
+		t.Errorf("plain strikethrough = %+v", plain)
+	}
+	nested := findElement(paragraphs[1].Children, "strikethrough")
+	if nested == nil || len(nested.Children) != 1 || nested.Children[0].Type != "strong" {
+		t.Errorf("nested strikethrough = %+v, want a strong child", nested)
+	}
+	if findElement(paragraphs[2].Children, "strikethrough") == nil {
+		t.Errorf("<s> element = %+v, want a strikethrough", paragraphs[2])
+	}
+}
+
+func TestTableCellAlignment(t *testing.T) {
+	table := findElement(parseTestMarkdown(t, "| Left | Center | Right |\n|:-----|:------:|------:|\n| a | b | c |\n"), "table")
+	if table == nil {
+		t.Fatal("no table element")
+	}
+
+	want := []string{"left", "center", "right"}
+	for _, cellType := range []string{"table_header_cell", "table_cell"} {
+		cells := findElements(table.Children, cellType)
+		if len(cells) != 3 {
+			t.Fatalf("%s cells = %+v, want three", cellType, cells)
+		}
+		for i, cell := range cells {
+			if cell.Attributes.Align != want[i] {
+				t.Errorf("%s %d align = %q, want %q", cellType, i, cell.Attributes.Align, want[i])
+			}
+		}
+	}
+}
+
+func TestHTMLTableStyleAlignment(t *testing.T) {
+	elements := parseHTMLToElements(context.Background(), `<table><tr><td style="color: red; text-align: Center">x</td><td>y</td></tr></table>`)
+	cells := findElements(elements, "table_cell")
+	if len(cells) != 2 || cells[0].Attributes.Align != "center" || cells[1].Attributes.Align != "" {
+		t.Errorf("cells = %+v, want center then unaligned", cells)
+	}
+}
+
+func TestDeepNestingIsTruncated(t *testing.T) {
+	stubReadmes(t, map[string]string{
+		"octo/quotes": strings.Repeat("<blockquote>", 500) + "deep" + strings.Repeat("</blockquote>", 500) + "\n",
+		"octo/lists":  nestedListMarkdown(300),
+	})
+
+	for _, repo := range []string{"quotes", "lists"} {
+		rec := getReadme(t, "owner=octo&repo="+repo, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", repo, rec.Code, rec.Body.String())
+		}
+		var doc MarkdownDocument
+		if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+			t.Fatalf("%s: decoding: %v", repo, err)
+		}
+		marker := findElement(doc.Content, "truncated")
+		if marker == nil || !marker.Attributes.Truncated {
+			t.Errorf("%s: no truncation marker in the content", repo)
+		}
+	}
+}
+
+// Helper function to build a list nested depth levels deep
+func nestedListMarkdown(depth int) string {
+	var sb strings.Builder
+	for i := 0; i < depth; i++ {
+		sb.WriteString(strings.Repeat("  ", i) + "- level\n")
+	}
+	return sb.String()
+}
+
+func TestInlineChildrenKeepSourceOrder(t *testing.T) {
+	// Tight list items hold their inline children directly
+	containers := map[string]string{
+		"See **the** `config` file [here](x)\n":   "paragraph",
+		"- See **the** `config` file [here](x)\n": "list_item",
+		"> See **the** `config` file [here](x)\n": "paragraph",
+	}
+	for source, containerType := range containers {
+		elements := parseTestMarkdown(t, source)
+		container := findElement(elements, containerType)
+		if container == nil {
+			t.Fatalf("%q: no %s in %+v", source, containerType, elements)
+		}
+
+		var types []string
+		for _, child := range container.Children {
+			types = append(types, child.Type)
+		}
+		if got := strings.Join(types, ","); got != "text,strong,code,text,link" {
+			t.Errorf("%q: children = %s, want text,strong,code,text,link", source, got)
+		}
+		if codes := findElements(elements, "code"); len(codes) != 1 || codes[0].Content != "config" {
+			t.Errorf("%q: code elements = %+v, want one inside the %s", source, codes, containerType)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// HTTP Handler serving the OpenAPI document
+func handleOpenAPIRequest(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(buildOpenAPISpec(apiRoutes())); err != nil {
+		logf(r.Context(), "Error encoding response: %v", err)
+	}
+}
+
+// Build an OpenAPI 3 document from the route table
+func buildOpenAPISpec(routes []route) map[string]any {
+	schemas := make(map[string]any)
+	paths := make(map[string]any)
+
+	for _, rt := range routes {
+		operation := map[string]any{
+			"summary": rt.Summary,
+		}
+
+		var params []map[string]any
+		for _, p := range rt.Params {
+			params = append(params, map[string]any{
+				"name":        p.Name,
+				"in":          "query",
+				"required":    p.Required,
+				"description": p.Description,
+				"schema":      map[string]any{"type": p.Type},
+			})
+		}
+		if len(params) > 0 {
+			operation["parameters"] = params
+		}
+
+		if rt.RequestBody != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": schemaFor(reflect.TypeOf(rt.RequestBody), schemas)},
+				},
+			}
+		}
+
+		okResponse := map[string]any{"description": "Success"}
+		if rt.Response != nil {
+			okResponse["content"] = map[string]any{
+				"application/json": map[string]any{"schema": schemaFor(reflect.TypeOf(rt.Response), schemas)},
+			}
+		}
+		operation["responses"] = map[string]any{
+			"200": okResponse,
+			"default": map[string]any{
+				"description": "Error",
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": schemaFor(reflect.TypeOf(ErrorResponse{}), schemas)},
+				},
+			},
+		}
+
+		paths[rt.Path] = map[string]any{strings.ToLower(rt.Method): operation}
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "README parser API",
+			"version": schemaVersion,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas,
+		},
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Derive a JSON schema from a Go type, registering named structs as components
+func schemaFor(t reflect.Type, schemas map[string]any) map[string]any {
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem(), schemas)
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// Raw JSON and byte slices are free-form
+			return map[string]any{}
+		}
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem(), schemas)}
+	case reflect.Struct:
+		ref := map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+		if _, seen := schemas[t.Name()]; seen {
+			return ref
+		}
+
+		// Register before walking fields so recursive types terminate
+		properties := make(map[string]any)
+		schemas[t.Name()] = map[string]any{"type": "object", "properties": properties}
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = schemaFor(field.Type, schemas)
+		}
+		return ref
+	}
+
+	return map[string]any{}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Response formats
+const (
+	formatJSON     = "json"
+	formatDOT      = "dot"
+	formatMarkdown = "markdown"
+	formatText     = "text"
+	formatHTML     = "html"
+)
+
+// Request options parsed from the /readme query string
+type readmeOptions struct {
+	Paginate       bool
+	Offset         int
+	Limit          int
+	AltFallback    bool
+	Validate       bool
+	TOCDepth       int
+	TOCStyle       string
+	Topics         bool
+	SplitOnHR      bool
+	Sanitize       bool
+	DebugRaw       bool
+	Images         bool
+	Casing         string
+	Path           string
+	SourceMarkdown bool
+	Format         string
+	Imports        bool
+	TextContent    bool
+	CompareBase    string
+	CompareHead    string
+	Truncate       int
+	FixAnchors     bool
+	Release        string
+	Permalinks     bool
+	APIRef         bool
+	Ref            string
+	CleanText      bool
+	Anchors        bool
+	Emoji          bool
+	Positions      bool
+	Markdown       markdownOptions
+}
+
+// Boolean options that can be enabled by name
+var featureOptions = map[string]func(*readmeOptions){
+	"altfallback": func(o *readmeOptions) { o.AltFallback = true },
+	"validate":    func(o *readmeOptions) { o.Validate = true },
+	"topics":      func(o *readmeOptions) { o.Topics = true },
+	"splitonhr":   func(o *readmeOptions) { o.SplitOnHR = true },
+	"sanitize":    func(o *readmeOptions) { o.Sanitize = true },
+	"images":      func(o *readmeOptions) { o.Images = true },
+	"sourcemd":    func(o *readmeOptions) { o.SourceMarkdown = true },
+	"imports":     func(o *readmeOptions) { o.Imports = true },
+	"textcontent": func(o *readmeOptions) { o.TextContent = true },
+	"fixanchors":  func(o *readmeOptions) { o.FixAnchors = true },
+	"permalinks":  func(o *readmeOptions) { o.Permalinks = true },
+	"apiref":      func(o *readmeOptions) { o.APIRef = true },
+	"cleantext":   func(o *readmeOptions) { o.CleanText = true },
+	"anchors":     func(o *readmeOptions) { o.Anchors = true },
+	"emoji":       func(o *readmeOptions) { o.Emoji = true },
+	"positions":   func(o *readmeOptions) { o.Positions = true },
+	// Always on, accepted so feature lists can name them
+	"toc":     func(*readmeOptions) {},
+	"rewrite": func(*readmeOptions) {},
+}
+
+// Helper function to list feature names in a stable order
+func featureNames() []string {
+	names := make([]string, 0, len(featureOptions))
+	for name := range featureOptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parse request options from query parameters
+func parseReadmeOptions(query url.Values) (readmeOptions, error) {
+	opts := readmeOptions{
+		TOCDepth: 6,
+		TOCStyle: tocStyleFlat,
+		Format:   formatJSON,
+		Sanitize: true,
+		Emoji:    true,
+		Markdown: defaultMarkdownOptions(),
+	}
+
+	// Pagination over top-level elements
+	if query.Has("offset") || query.Has("limit") {
+		opts.Paginate = true
+
+		offset, err := parseNonNegativeInt(query, "offset")
+		if err != nil {
+			return readmeOptions{}, err
+		}
+		limit, err := parseNonNegativeInt(query, "limit")
+		if err != nil {
+			return readmeOptions{}, err
+		}
+		opts.Offset = offset
+		opts.Limit = limit
+	}
+
+	// Boolean features, enabled individually or through ?features=a,b
+	for name, enable := range featureOptions {
+		if query.Get(name) == "true" {
+			enable(&opts)
+		}
+	}
+	if raw := query.Get("features"); raw != "" {
+		for _, name := range strings.Split(raw, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			enable, ok := featureOptions[name]
+			if !ok {
+				return readmeOptions{}, fmt.Errorf("unknown feature %q, valid features are: %s",
+					name, strings.Join(featureNames(), ", "))
+			}
+			enable(&opts)
+		}
+	}
+
+	// Raw HTML is sanitized and shortcodes become emoji unless explicitly turned off
+	if query.Get("sanitize") == "false" {
+		opts.Sanitize = false
+	}
+	if query.Get("emoji") == "false" {
+		opts.Emoji = false
+	}
+
+	// Markdown parser features
+	for name, flag := range map[string]*bool{
+		"hardbreaks": &opts.Markdown.HardLineBreaks,
+		"tables":     &opts.Markdown.Tables,
+		"footnotes":  &opts.Markdown.Footnotes,
+	} {
+		if raw := query.Get(name); raw != "" {
+			value, err := strconv.ParseBool(raw)
+			if err != nil {
+				return readmeOptions{}, fmt.Errorf("%s must be true or false", name)
+			}
+			*flag = value
+		}
+	}
+
+	opts.Path = query.Get("path")
+	if opts.Path != "" {
+		if err := validateFilePath(opts.Path); err != nil {
+			return readmeOptions{}, err
+		}
+	}
+
+	truncate, err := parseNonNegativeInt(query, "truncate")
+	if err != nil {
+		return readmeOptions{}, err
+	}
+	opts.Truncate = truncate
+
+	// Branch, tag or commit to fetch from
+	opts.Ref = query.Get("ref")
+
+	// README as of a release tag
+	opts.Release = query.Get("release")
+	if opts.Release != "" && opts.Path != "" {
+		return readmeOptions{}, fmt.Errorf("release cannot be combined with path")
+	}
+	if opts.Release != "" && opts.Ref != "" {
+		return readmeOptions{}, fmt.Errorf("release cannot be combined with ref")
+	}
+
+	// Changed sections between two refs
+	if raw := query.Get("compare"); raw != "" {
+		base, head, err := parseCompareRange(raw)
+		if err != nil {
+			return readmeOptions{}, err
+		}
+		opts.CompareBase, opts.CompareHead = base, head
+
+		if opts.Release != "" || opts.Ref != "" {
+			return readmeOptions{}, fmt.Errorf("compare cannot be combined with release or ref")
+		}
+	}
+
+	// Response format
+	if format := query.Get("format"); format != "" {
+		switch format {
+		case formatJSON, formatDOT, formatMarkdown, formatText, formatHTML:
+		default:
+			return readmeOptions{}, fmt.Errorf("unknown format %q", format)
+		}
+		opts.Format = format
+	}
+	if opts.Format == formatHTML && opts.Path != "" && !markdownExtensions[strings.ToLower(path.Ext(opts.Path))] {
+		return readmeOptions{}, fmt.Errorf("format=html is only available for markdown files")
+	}
+
+	// Canonical markdown: ATX headings, "-" bullets, fenced code and inline links
+	if query.Get("canonical") == "true" {
+		if opts.Format != formatJSON && opts.Format != formatMarkdown {
+			return readmeOptions{}, fmt.Errorf("canonical cannot be combined with format=%s", opts.Format)
+		}
+		opts.Format = formatMarkdown
+	}
+
+	// JSON key casing
+	if casing := query.Get("casing"); casing != "" {
+		if casing != casingSnake && casing != casingCamel {
+			return readmeOptions{}, fmt.Errorf("casing must be %q or %q", casingSnake, casingCamel)
+		}
+		opts.Casing = casing
+	}
+
+	// Raw upstream output is only exposed in debug mode
+	if query.Get("debugraw") == "true" {
+		if !debugModeEnabled() {
+			return readmeOptions{}, fmt.Errorf("debugraw requires the server to run with DEBUG=true")
+		}
+		opts.DebugRaw = true
+	}
+
+	// Table of contents depth and layout
+	if raw := query.Get("tocdepth"); raw != "" {
+		depth, err := strconv.Atoi(raw)
+		if err != nil || depth < 1 || depth > 6 {
+			return readmeOptions{}, fmt.Errorf("tocdepth must be between 1 and 6")
+		}
+		opts.TOCDepth = depth
+	}
+	if style := query.Get("tocstyle"); style != "" {
+		if style != tocStyleFlat && style != tocStyleNested {
+			return readmeOptions{}, fmt.Errorf("tocstyle must be %q or %q", tocStyleNested, tocStyleFlat)
+		}
+		opts.TOCStyle = style
+	}
+
+	return opts, nil
+}
+
+// Characters GitHub allows in account and repository names
+var (
+	ownerNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,38}$`)
+	repoNamePattern  = regexp.MustCompile(`^[A-Za-z0-9._-]{1,100}$`)
+)
+
+// Helper function to reject owner and repository names GitHub would not accept
+func validateRepository(owner, repo string) error {
+	if !ownerNamePattern.MatchString(owner) {
+		return fmt.Errorf("invalid owner %q, expected letters, digits, hyphens or underscores", owner)
+	}
+	if !repoNamePattern.MatchString(repo) || repo == "." || repo == ".." {
+		return fmt.Errorf("invalid repository %q, expected letters, digits, hyphens, underscores or dots", repo)
+	}
+	return nil
+}
+
+// Helper function to reject file paths that are absolute or leave the repository
+func validateFilePath(filePath string) error {
+	if strings.HasPrefix(filePath, "/") || strings.Contains(filePath, "\\") {
+		return fmt.Errorf("path must be relative to the repository root")
+	}
+	for _, segment := range strings.Split(filePath, "/") {
+		if segment == ".." {
+			return fmt.Errorf("path must not contain .. segments")
+		}
+	}
+	return nil
+}
+
+// Helper function to read an optional non-negative integer parameter
+func parseNonNegativeInt(query url.Values, name string) (int, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", name)
+	}
+	return value, nil
+}
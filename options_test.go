@@ -0,0 +1,107 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestFeaturesParamEnablesOptions(t *testing.T) {
+	opts := testOptions(t, "features=toc,emoji,sanitize,rewrite,Topics,+anchors")
+	if !opts.Topics || !opts.Anchors || !opts.Emoji || !opts.Sanitize {
+		t.Errorf("features were not enabled: %+v", opts)
+	}
+}
+
+func TestFeaturesParamMatchesIndividualParams(t *testing.T) {
+	combined := testOptions(t, "features=validate,permalinks")
+	individual := testOptions(t, "validate=true&permalinks=true")
+	if combined.Validate != individual.Validate || combined.Permalinks != individual.Permalinks {
+		t.Errorf("features = %+v, individual params = %+v", combined, individual)
+	}
+}
+
+func TestUnknownFeatureListsValidFeatures(t *testing.T) {
+	_, err := parseReadmeOptions(url.Values{"features": {"topics,bogus"}})
+	if err == nil {
+		t.Fatal("unknown feature was accepted")
+	}
+	if !strings.Contains(err.Error(), `"bogus"`) || !strings.Contains(err.Error(), "topics") {
+		t.Errorf("error = %q, want the unknown name and the valid features", err)
+	}
+}
+
+func TestMarkdownFlagsChangeParsing(t *testing.T) {
+	softWrapped := "first line\nsecond line\n"
+	if findElement(parseTestMarkdownDoc(t, softWrapped, "").Content, "line_break") == nil {
+		t.Error("default: soft wrap did not become a line break")
+	}
+	if el := findElement(parseTestMarkdownDoc(t, softWrapped, "hardbreaks=false").Content, "line_break"); el != nil {
+		t.Error("hardbreaks=false: soft wrap became a line break")
+	}
+
+	table := "| a | b |\n|---|---|\n| 1 | 2 |\n"
+	if findElement(parseTestMarkdownDoc(t, table, "").Content, "table") == nil {
+		t.Error("default: table was not parsed")
+	}
+	if findElement(parseTestMarkdownDoc(t, table, "tables=false").Content, "table") != nil {
+		t.Error("tables=false: table was parsed")
+	}
+
+	footnote := "Claim[^1].\n\n[^1]: Source.\n"
+	if findElement(parseTestMarkdownDoc(t, footnote, "").Content, "footnote_ref") != nil {
+		t.Error("default: footnote was parsed")
+	}
+	if findElement(parseTestMarkdownDoc(t, footnote, "footnotes=true").Content, "footnote_ref") == nil {
+		t.Error("footnotes=true: footnote was not parsed")
+	}
+}
+
+func TestHardBreaksChangeRenderedHTML(t *testing.T) {
+	stubReadmes(t, map[string]string{"octo/demo": "first line\nsecond line\n"})
+
+	withBreaks := getReadme(t, "owner=octo&repo=demo&format=html", nil).Body.String()
+	withoutBreaks := getReadme(t, "owner=octo&repo=demo&format=html&hardbreaks=false", nil).Body.String()
+	if !strings.Contains(withBreaks, "<br") {
+		t.Errorf("default HTML %q lacks a line break", withBreaks)
+	}
+	if strings.Contains(withoutBreaks, "<br") {
+		t.Errorf("hardbreaks=false HTML %q has a line break", withoutBreaks)
+	}
+
+	if _, err := parseReadmeOptions(url.Values{"hardbreaks": {"maybe"}}); err == nil {
+		t.Error("non-boolean hardbreaks was accepted")
+	}
+}
+
+func TestValidateRepository(t *testing.T) {
+	valid := [][2]string{{"octo", "demo"}, {"octo-org", "my_repo.js"}, {"a1", ".github"}}
+	for _, name := range valid {
+		if err := validateRepository(name[0], name[1]); err != nil {
+			t.Errorf("%s/%s: %v", name[0], name[1], err)
+		}
+	}
+
+	invalid := [][2]string{{"octo/evil", "demo"}, {"octo", "de/mo"}, {"octo", "my repo"}, {"oc to", "demo"}, {"octo", ".."}, {"octo.org", "demo"}}
+	for _, name := range invalid {
+		if err := validateRepository(name[0], name[1]); err == nil {
+			t.Errorf("%s/%s was accepted", name[0], name[1])
+		}
+	}
+}
+
+func TestInvalidRepositoryIsRejectedBeforeGitHub(t *testing.T) {
+	calls := 0
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) { calls++ })
+
+	for _, query := range []string{"owner=octo&repo=de%2Fmo", "owner=octo&repo=my+repo", "owner=..&repo=demo"} {
+		status, errResp := getReadmeError(t, query)
+		if status != http.StatusBadRequest || errResp.Code != errorCodeBadRequest {
+			t.Errorf("%s: got %d %+v, want 400", query, status, errResp)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("invalid names made %d GitHub calls", calls)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+// Pagination describes the slice of top-level elements returned
+type Pagination struct {
+	Offset  int  `json:"offset"`
+	Limit   int  `json:"limit"`
+	Total   int  `json:"total"`
+	HasMore bool `json:"hasMore"`
+}
+
+// Slice the top-level elements of a document, keeping subtrees intact.
+// A limit of zero returns everything from offset onwards.
+func paginateDocument(doc MarkdownDocument, offset, limit int) MarkdownDocument {
+	total := len(doc.Content)
+
+	start := min(offset, total)
+	end := total
+	if limit > 0 {
+		end = min(start+limit, total)
+	}
+
+	doc.Content = doc.Content[start:end]
+	doc.Pagination = &Pagination{
+		Offset:  offset,
+		Limit:   limit,
+		Total:   total,
+		HasMore: end < total,
+	}
+
+	return doc
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	neturl "net/url"
+)
+
+// Set a GitHub permalink on every heading that has an ID
+func applyPermalinks(elements []Element, pageURL string) {
+	for i := range elements {
+		el := &elements[i]
+		if el.Type == "heading" && el.Attributes.ID != "" {
+			el.Attributes.Permalink = pageURL + "#" + el.Attributes.ID
+		}
+		applyPermalinks(el.Children, pageURL)
+	}
+}
+
+// Helper function to build the GitHub page a README or file request renders
+func readmePageURL(repoURL, owner, repo string, opts readmeOptions) string {
+	if repoURL == "" {
+		return ""
+	}
+
+	file := opts.Path
+	if file == "" {
+		file = "README.md"
+	}
+	ref := requestRef(owner, repo, opts)
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	// Branch names may contain slashes, which GitHub reads as part of the ref
+	blobPath := (&neturl.URL{Path: ref + "/" + repoPath(file)}).EscapedPath()
+	return fmt.Sprintf("%s/blob/%s", repoURL, blobPath)
+}
+
+// Helper function to build the GitHub page of a wiki page
+func wikiPageURL(repoURL, page string) string {
+	if repoURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/wiki/%s", repoURL, neturl.PathEscape(page))
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestHeadingPermalinks(t *testing.T) {
+	stubReadmes(t, map[string]string{"octo/demo": "# Demo\n\n## Getting started\n"})
+
+	tests := map[string]string{
+		"owner=octo&repo=demo&permalinks=true":        "https://github.com/octo/demo/blob/HEAD/README.md#getting-started",
+		"owner=octo&repo=demo&permalinks=true&ref=v1": "https://github.com/octo/demo/blob/v1/README.md#getting-started",
+	}
+	for query, want := range tests {
+		rec := getReadme(t, query, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", query, rec.Code, rec.Body.String())
+		}
+		var doc MarkdownDocument
+		if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+			t.Fatalf("%s: decoding: %v", query, err)
+		}
+		headings := findElements(doc.Content, "heading")
+		if len(headings) != 2 || headings[1].Attributes.Permalink != want {
+			t.Errorf("%s: headings = %+v, want permalink %s", query, headings, want)
+		}
+	}
+
+	rec := getReadme(t, "owner=octo&repo=demo", nil)
+	var doc MarkdownDocument
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decoding: %v", err)
+	}
+	if heading := findElement(doc.Content, "heading"); heading == nil || heading.Attributes.Permalink != "" {
+		t.Errorf("permalink set without the option: %+v", heading)
+	}
+}
+
+func TestFilePagePermalinkBase(t *testing.T) {
+	opts := testOptions(t, "path=docs/Setup Guide.md&ref=feature/docs")
+	got := readmePageURL("https://github.com/octo/demo", "octo", "demo", opts)
+	if want := "https://github.com/octo/demo/blob/feature/docs/docs/Setup%20Guide.md"; got != want {
+		t.Errorf("page URL = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Default reading speed used for reading time estimates
+const defaultWordsPerMinute = 200
+
+// Reading speed, configured from READING_WPM at startup
+var wordsPerMinute = defaultWordsPerMinute
+
+// Count words of visible text, skipping code blocks and footnote markers
+func countWords(elements []Element) int {
+	count := 0
+	for _, el := range elements {
+		switch el.Type {
+		case "code_block", "footnote_ref":
+			continue
+		case "image":
+			count += wordsIn(el.Attributes.Alt)
+			continue
+		}
+
+		// Headings repeat their children's text in Content
+		if len(el.Children) > 0 {
+			count += countWords(el.Children)
+		} else {
+			count += wordsIn(el.Content)
+		}
+	}
+	return count
+}
+
+// Helper function to count words in text, ignoring bare punctuation
+func wordsIn(text string) int {
+	count := 0
+	for _, field := range strings.Fields(text) {
+		if strings.IndexFunc(field, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// Helper function to estimate whole minutes to read, rounding up
+func readingTimeMinutes(words int) int {
+	return (words + wordsPerMinute - 1) / wordsPerMinute
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWordCountAndReadingTime(t *testing.T) {
+	// Heading, paragraph and alt text count; the code block does not
+	source := "# Overview\n\n" + strings.Repeat("word ", 400) + "\n\n![Two words](shot.png)\n\n```\nnot counted at all\n```\n\n- - -\n"
+	metadata := parseTestMarkdownDoc(t, source, "").Metadata
+	if metadata.WordCount != 403 {
+		t.Errorf("word count = %d, want 403", metadata.WordCount)
+	}
+	if metadata.ReadingTimeMinutes != 3 {
+		t.Errorf("reading time = %d minutes, want 3", metadata.ReadingTimeMinutes)
+	}
+}
+
+func TestReadingTimeUsesConfiguredSpeed(t *testing.T) {
+	previous := wordsPerMinute
+	wordsPerMinute = 100
+	t.Cleanup(func() { wordsPerMinute = previous })
+
+	for words, want := range map[int]int{0: 0, 1: 1, 100: 1, 101: 2} {
+		if got := readingTimeMinutes(words); got != want {
+			t.Errorf("readingTimeMinutes(%d) = %d, want %d", words, got, want)
+		}
+	}
+}
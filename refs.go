@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Configured docs branch per repository, keyed by lowercased owner/repo
+var defaultRefs = map[string]string{}
+
+// Load default refs from DEFAULT_REFS, a JSON object mapping owner/repo to
+// a ref, or from the JSON file named by DEFAULT_REFS_FILE
+func defaultRefsFromEnv() (map[string]string, error) {
+	data := []byte(os.Getenv("DEFAULT_REFS"))
+	if path := os.Getenv("DEFAULT_REFS_FILE"); path != "" {
+		if len(data) > 0 {
+			return nil, fmt.Errorf("set only one of DEFAULT_REFS and DEFAULT_REFS_FILE")
+		}
+		fileData, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading default refs: %w", err)
+		}
+		data = fileData
+	}
+	if len(data) == 0 {
+		return map[string]string{}, nil
+	}
+
+	var configured map[string]string
+	if err := json.Unmarshal(data, &configured); err != nil {
+		return nil, fmt.Errorf("parsing default refs: %w", err)
+	}
+
+	refs := make(map[string]string, len(configured))
+	for name, ref := range configured {
+		owner, repo, ok := strings.Cut(name, "/")
+		if !ok || owner == "" || repo == "" || ref == "" {
+			return nil, fmt.Errorf("default ref entry %q must map owner/repo to a ref", name)
+		}
+		refs[repositoryCacheKey(owner, repo)] = ref
+	}
+	return refs, nil
+}
+
+// Get the ref a request renders: an explicit ref, release or compare head,
+// falling back to the configured default for the repository
+func requestRef(owner, repo string, opts readmeOptions) string {
+	switch {
+	case opts.Ref != "":
+		return opts.Ref
+	case opts.Release != "":
+		return opts.Release
+	case opts.CompareHead != "":
+		return opts.CompareHead
+	}
+	return defaultRefFor(owner, repo)
+}
+
+// Get the configured ref for a repository. An empty ref makes GitHub use
+// the repository's default branch.
+func defaultRefFor(owner, repo string) string {
+	return defaultRefs[repositoryCacheKey(owner, repo)]
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestReadmeRefParameter(t *testing.T) {
+	var readmeQueries []string
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/octo/demo/readme" {
+			readmeQueries = append(readmeQueries, r.URL.RawQuery)
+			fmt.Fprintf(w, `{"content":%q}`, base64.StdEncoding.EncodeToString([]byte("# Demo")))
+			return
+		}
+		fmt.Fprint(w, `{"name":"demo","default_branch":"main"}`)
+	})
+
+	tests := []struct {
+		query     string
+		wantQuery string
+		wantRef   string
+	}{
+		{"owner=octo&repo=demo", "", "main"},
+		{"owner=octo&repo=demo&ref=develop", "ref=develop", "develop"},
+	}
+	for _, tt := range tests {
+		readmeQueries = nil
+		rec := getReadme(t, tt.query, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", tt.query, rec.Code, rec.Body.String())
+		}
+		var doc MarkdownDocument
+		if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+			t.Fatalf("%s: decoding: %v", tt.query, err)
+		}
+		if len(readmeQueries) != 1 || readmeQueries[0] != tt.wantQuery {
+			t.Errorf("%s: README requested with %q, want %q", tt.query, readmeQueries, tt.wantQuery)
+		}
+		if doc.Metadata.Ref != tt.wantRef {
+			t.Errorf("%s: metadata ref = %q, want %q", tt.query, doc.Metadata.Ref, tt.wantRef)
+		}
+	}
+}
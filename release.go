@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"time"
+)
+
+// Returned when the requested release tag does not exist
+var errReleaseNotFound = errors.New("release not found")
+
+// ReleaseInfo The release a versioned README was fetched for
+type ReleaseInfo struct {
+	Tag         string    `json:"tag"`
+	Name        string    `json:"name"`
+	Body        string    `json:"body"`
+	PublishedAt time.Time `json:"publishedAt"`
+}
+
+// Process the README as of a release tag, with the release in the metadata
+func processReleaseReadme(ctx context.Context, owner, repo string, opts readmeOptions) (MarkdownDocument, error) {
+	var release ReleaseInfo
+	readmeContent, metadata, err := fetchWithMetadata(ctx, owner, repo, func(ctx context.Context) (string, error) {
+		var err error
+		release, err = getRelease(ctx, owner, repo, opts.Release)
+		if err != nil {
+			return "", fmt.Errorf("fetching release: %w", err)
+		}
+
+		content, err := getReadmeContent(ctx, owner, repo, release.Tag)
+		if err != nil {
+			return "", fmt.Errorf("fetching readme: %w", err)
+		}
+		return content, nil
+	})
+	if err != nil {
+		return MarkdownDocument{}, err
+	}
+	metadata.Release = &release
+
+	return renderDocument(ctx, readmeContent, metadata, opts), nil
+}
+
+// Fetch a release by its tag name
+func getRelease(ctx context.Context, owner, repo, tag string) (ReleaseInfo, error) {
+	url := githubAPIURL("/repos/%s/%s/releases/tags/%s", neturl.PathEscape(owner), neturl.PathEscape(repo), neturl.PathEscape(tag))
+
+	resp, body, err := doGitHubRequest(ctx, url, githubJSONMediaType)
+	if err != nil {
+		return ReleaseInfo{}, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ReleaseInfo{}, fmt.Errorf("%w: %q", errReleaseNotFound, tag)
+	}
+	recordDebugResponse(ctx, "release", body)
+	if err := checkGitHubResponse(resp, body); err != nil {
+		return ReleaseInfo{}, err
+	}
+
+	var releaseResp struct {
+		TagName     string    `json:"tag_name"`
+		Name        string    `json:"name"`
+		Body        string    `json:"body"`
+		PublishedAt time.Time `json:"published_at"`
+	}
+	if err := json.Unmarshal(body, &releaseResp); err != nil {
+		return ReleaseInfo{}, fmt.Errorf("parsing response: %w", err)
+	}
+
+	return ReleaseInfo{
+		Tag:         releaseResp.TagName,
+		Name:        releaseResp.Name,
+		Body:        releaseResp.Body,
+		PublishedAt: releaseResp.PublishedAt,
+	}, nil
+}
@@ -0,0 +1,419 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Element types rendered inline rather than as blocks
+var inlineElementTypes = map[string]bool{
+	"text":          true,
+	"strong":        true,
+	"emphasis":      true,
+	"code":          true,
+	"link":          true,
+	"image":         true,
+	"time":          true,
+	"strikethrough": true,
+	"line_break":    true,
+	"footnote_ref":  true,
+	"span":          true,
+}
+
+// Characters with inline meaning in markdown text
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+	"#", `\#`,
+	"[", `\[`,
+	"]", `\]`,
+	"~", `\~`,
+)
+
+// RenderMarkdown Serialize an element tree back to markdown
+func RenderMarkdown(content []Element) string {
+	var blocks []string
+	for _, group := range groupInlineElements(content) {
+		blocks = append(blocks, renderMarkdownBlock(group, ""))
+	}
+	return joinBlocks(blocks)
+}
+
+// RenderPlainText Serialize an element tree to unformatted text
+func RenderPlainText(content []Element) string {
+	var blocks []string
+	for _, group := range groupInlineElements(content) {
+		blocks = append(blocks, renderPlainBlock(group, ""))
+	}
+	return joinBlocks(blocks)
+}
+
+// Helper function to wrap runs of inline elements into paragraphs
+func groupInlineElements(elements []Element) []Element {
+	var groups []Element
+	var run []Element
+	flush := func() {
+		if len(run) > 0 {
+			groups = append(groups, Element{Type: "paragraph", Children: run})
+			run = nil
+		}
+	}
+
+	for _, el := range elements {
+		if inlineElementTypes[el.Type] {
+			run = append(run, el)
+			continue
+		}
+		flush()
+		groups = append(groups, el)
+	}
+	flush()
+
+	return groups
+}
+
+// Helper function to separate blocks with blank lines
+func joinBlocks(blocks []string) string {
+	var nonEmpty []string
+	for _, block := range blocks {
+		if strings.TrimSpace(block) != "" {
+			nonEmpty = append(nonEmpty, strings.TrimRight(block, "\n"))
+		}
+	}
+	if len(nonEmpty) == 0 {
+		return ""
+	}
+	return strings.Join(nonEmpty, "\n\n") + "\n"
+}
+
+// Helper function to render the text of an inline-only element, falling back to Content
+func elementInlineMarkdown(el Element) string {
+	if len(el.Children) == 0 {
+		return markdownEscaper.Replace(el.Content)
+	}
+	return renderMarkdownInline(el.Children)
+}
+
+func renderMarkdownBlock(el Element, indent string) string {
+	switch el.Type {
+	case "heading":
+		level, err := strconv.Atoi(el.Attributes.Level)
+		if err != nil || level < 1 || level > 6 {
+			level = 1
+		}
+		return strings.Repeat("#", level) + " " + elementInlineMarkdown(el)
+
+	case "paragraph":
+		return indentLines(renderMarkdownInline(el.Children), indent)
+
+	case "code_block":
+		fence := codeFence(el.Content, "```")
+		return indentLines(fence+el.Attributes.Language+"\n"+strings.TrimRight(el.Content, "\n")+"\n"+fence, indent)
+
+	case "horizontal_rule":
+		return indent + "---"
+
+	case "unordered_list", "ordered_list":
+		var items []string
+		number := 1
+		for _, item := range el.Children {
+			marker := "- "
+			if el.Type == "ordered_list" {
+				marker = strconv.Itoa(number) + ". "
+				number++
+			}
+			items = append(items, renderMarkdownListItem(item, indent, marker))
+		}
+		return strings.Join(items, "\n")
+
+	case "table":
+		return renderMarkdownTable(el, indent)
+
+	case "blockquote":
+		return indentLines(quoteLines(renderMarkdownChildren(el.Children)), indent)
+
+	case "admonition":
+		body := "[!" + strings.ToUpper(el.Attributes.Kind) + "]\n" + renderMarkdownChildren(el.Children)
+		return indentLines(quoteLines(body), indent)
+
+	case "definition_list":
+		// A blank line separates entries, otherwise a term continues the previous description
+		var entries []string
+		for _, child := range el.Children {
+			if child.Type != "definition_term" {
+				entries = append(entries, indent+": "+renderMarkdownInline(child.Children))
+				continue
+			}
+			var inline []Element
+			var lines []string
+			for _, part := range child.Children {
+				if part.Type == "definition_description" {
+					lines = append(lines, indent+": "+renderMarkdownInline(part.Children))
+				} else {
+					inline = append(inline, part)
+				}
+			}
+			term := indent + elementInlineMarkdown(Element{Content: child.Content, Children: inline})
+			entries = append(entries, strings.Join(append([]string{term}, lines...), "\n"))
+		}
+		return strings.Join(entries, "\n\n")
+	}
+
+	// Unknown containers keep their content
+	if len(el.Children) > 0 {
+		return indentLines(renderMarkdownChildren(el.Children), indent)
+	}
+	return indent + markdownEscaper.Replace(el.Content)
+}
+
+// Helper function to render child elements as unindented blocks
+func renderMarkdownChildren(children []Element) string {
+	var blocks []string
+	for _, group := range groupInlineElements(children) {
+		blocks = append(blocks, renderMarkdownBlock(group, ""))
+	}
+	return strings.TrimRight(joinBlocks(blocks), "\n")
+}
+
+// Helper function to render a list item with nested blocks indented under it
+func renderMarkdownListItem(item Element, indent, marker string) string {
+	var inline []Element
+	var nested []string
+	leading := true
+	childIndent := indent + strings.Repeat(" ", max(len(marker), 4))
+	for _, child := range item.Children {
+		if inlineElementTypes[child.Type] {
+			inline = append(inline, child)
+			continue
+		}
+		// Only the first paragraph shares the marker line
+		if child.Type == "paragraph" && leading {
+			inline = append(inline, child.Children...)
+			leading = false
+			continue
+		}
+		// Nested lists follow the item directly; other blocks need a blank line
+		if child.Type != "unordered_list" && child.Type != "ordered_list" {
+			nested = append(nested, "")
+		}
+		nested = append(nested, renderMarkdownBlock(child, childIndent))
+		leading = false
+	}
+
+	checkbox := ""
+	if item.Type == "task_list_item" {
+		checkbox = "[ ] "
+		if item.Attributes.Checked != nil && *item.Attributes.Checked {
+			checkbox = "[x] "
+		}
+	}
+
+	lines := []string{strings.TrimRight(indent+marker+checkbox+renderMarkdownInline(inline), " ")}
+	lines = append(lines, nested...)
+	return strings.Join(lines, "\n")
+}
+
+func renderMarkdownTable(table Element, indent string) string {
+	var rows [][]string
+	var aligns []string
+	var collect func([]Element)
+	collect = func(elements []Element) {
+		for _, el := range elements {
+			if el.Type != "table_row" {
+				collect(el.Children)
+				continue
+			}
+			var cells []string
+			for _, cell := range el.Children {
+				if cell.Type != "table_header_cell" && cell.Type != "table_cell" {
+					continue
+				}
+				cells = append(cells, strings.ReplaceAll(elementInlineMarkdown(cell), "|", `\|`))
+				if len(rows) == 0 {
+					aligns = append(aligns, cell.Attributes.Align)
+				}
+			}
+			rows = append(rows, cells)
+		}
+	}
+	collect(table.Children)
+
+	if len(rows) == 0 {
+		return ""
+	}
+
+	var lines []string
+	for i, row := range rows {
+		lines = append(lines, indent+"| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			// The first row is the header, which markdown always requires
+			separators := make([]string, len(row))
+			for j := range separators {
+				separators[j] = alignmentSeparator(aligns[j])
+			}
+			lines = append(lines, indent+"| "+strings.Join(separators, " | ")+" |")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Helper function to build a header separator cell for a column alignment
+func alignmentSeparator(align string) string {
+	switch align {
+	case "left":
+		return ":--"
+	case "center":
+		return ":-:"
+	case "right":
+		return "--:"
+	}
+	return "---"
+}
+
+func renderMarkdownInline(elements []Element) string {
+	var sb strings.Builder
+	for i, el := range elements {
+		if i > 0 && needsSpaceBetween(sb.String(), el) {
+			sb.WriteByte(' ')
+		}
+
+		switch el.Type {
+		case "text":
+			sb.WriteString(markdownEscaper.Replace(el.Content))
+		case "strong":
+			sb.WriteString("**" + elementInlineMarkdown(el) + "**")
+		case "emphasis":
+			sb.WriteString("*" + elementInlineMarkdown(el) + "*")
+		case "strikethrough":
+			sb.WriteString("~~" + elementInlineMarkdown(el) + "~~")
+		case "code":
+			fence := codeFence(el.Content, "`")
+			content := el.Content
+			if strings.HasPrefix(content, "`") || strings.HasSuffix(content, "`") {
+				content = " " + content + " "
+			}
+			sb.WriteString(fence + content + fence)
+		case "link":
+			sb.WriteString("[" + elementInlineMarkdown(el) + "](" + el.Attributes.Href + markdownTitle(el.Attributes.Title) + ")")
+		case "image":
+			sb.WriteString("![" + markdownEscaper.Replace(el.Attributes.Alt) + "](" + el.Attributes.Src + markdownTitle(el.Attributes.Title) + ")")
+		case "line_break":
+			// A backslash before the newline breaks the line in CommonMark
+			sb.WriteString("\\\n")
+		case "footnote_ref":
+			sb.WriteString("[^" + el.Attributes.ID + "]")
+		default:
+			sb.WriteString(elementInlineMarkdown(el))
+		}
+	}
+	// A break at the end of a block has nothing to separate
+	return strings.TrimSuffix(sb.String(), "\\\n")
+}
+
+// Helper function to decide whether adjacent inline elements need a space.
+// Text nodes are trimmed during parsing, so the original spacing is lost.
+func needsSpaceBetween(previous string, next Element) bool {
+	if previous == "" || strings.HasSuffix(previous, "\n") || next.Type == "line_break" || next.Type == "footnote_ref" {
+		return false
+	}
+	if next.Type == "text" && next.Content != "" && strings.ContainsRune(".,;:!?)", rune(next.Content[0])) {
+		return false
+	}
+	return true
+}
+
+// Helper function to format an optional link title
+func markdownTitle(title string) string {
+	if title == "" {
+		return ""
+	}
+	return ` "` + strings.ReplaceAll(title, `"`, `\"`) + `"`
+}
+
+// Helper function to pick a fence longer than any run of the fence character in content
+func codeFence(content, base string) string {
+	fence := base
+	for strings.Contains(content, fence) {
+		fence += base[:1]
+	}
+	return fence
+}
+
+func renderPlainBlock(el Element, indent string) string {
+	switch el.Type {
+	case "code_block":
+		return indentLines(strings.TrimRight(el.Content, "\n"), indent)
+	case "horizontal_rule":
+		return ""
+	case "unordered_list", "ordered_list":
+		var items []string
+		for _, item := range el.Children {
+			items = append(items, indent+"- "+plainText(item))
+		}
+		return strings.Join(items, "\n")
+	}
+
+	if len(el.Children) == 0 {
+		return indent + el.Content
+	}
+	if el.Type == "heading" || el.Type == "paragraph" {
+		return indentLines(plainText(el), indent)
+	}
+
+	var blocks []string
+	for _, group := range groupInlineElements(el.Children) {
+		blocks = append(blocks, renderPlainBlock(group, indent))
+	}
+	return strings.TrimRight(joinBlocks(blocks), "\n")
+}
+
+// Helper function to get the literal text of an element, markdown syntax is never added
+func plainText(el Element) string {
+	if len(el.Children) == 0 {
+		switch el.Type {
+		case "image":
+			return el.Attributes.Alt
+		case "line_break":
+			return "\n"
+		}
+		return el.Content
+	}
+
+	var sb strings.Builder
+	for _, child := range el.Children {
+		text := plainText(child)
+		if text == "" {
+			continue
+		}
+		if needsSpaceBetween(sb.String(), Element{Type: child.Type, Content: text}) {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(text)
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// Helper function to prefix every line of s as a blockquote
+func quoteLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Helper function to indent every non-empty line of s
+func indentLines(s, indent string) string {
+	if indent == "" {
+		return s
+	}
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestEscapedCharactersRoundTrip(t *testing.T) {
+	source := "\\*not italic\\* \\_x\\_ \\`y\\` \\[a\\] and \\# b\n"
+	literal := "*not italic* _x_ `y` [a] and # b\n"
+
+	elements := parseTestMarkdown(t, source)
+	if got := RenderPlainText(elements); got != literal {
+		t.Errorf("plain text = %q, want %q", got, literal)
+	}
+
+	rendered := RenderMarkdown(elements)
+	reparsed := parseTestMarkdown(t, rendered)
+	if got := RenderPlainText(reparsed); got != literal {
+		t.Errorf("re-serialized %q reparsed as %q, want %q", rendered, got, literal)
+	}
+	for _, formatted := range []string{"emphasis", "strong", "code", "link"} {
+		if findElement(reparsed, formatted) != nil {
+			t.Errorf("re-serialized %q reintroduced a %s element", rendered, formatted)
+		}
+	}
+}
+
+func TestEscapedHeadingMarkerStaysText(t *testing.T) {
+	elements := []Element{{Type: "paragraph", Children: []Element{{Type: "text", Content: "# not a heading"}}}}
+	rendered := RenderMarkdown(elements)
+	if !strings.HasPrefix(rendered, `\#`) {
+		t.Errorf("rendered %q, want the # escaped", rendered)
+	}
+	if findElement(parseTestMarkdown(t, rendered), "heading") != nil {
+		t.Errorf("re-serialized %q parsed as a heading", rendered)
+	}
+}
+
+func TestCanonicalMarkdownIsIdempotent(t *testing.T) {
+	source := "Title\n=====\n\n* one\n  * nested\n* two\n\n    second paragraph\n\nCode:\n\n    indented()\n\n~~~go\nfmt.Println()\n~~~\n\nSee [the docs][docs].\n\n[docs]: https://example.com/docs\n"
+	stubReadmes(t, map[string]string{"octo/styled": source})
+
+	rec := getReadme(t, "owner=octo&repo=styled&canonical=true", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+	}
+	canonical := rec.Body.String()
+	for _, want := range []string{"# Title\n", "- one\n    - nested\n", "- two\n\n    second paragraph\n", "```\nindented()\n```", "```go\nfmt.Println()\n```", "[the docs](https://example.com/docs)"} {
+		if !strings.Contains(canonical, want) {
+			t.Errorf("canonical output lacks %q:\n%s", want, canonical)
+		}
+	}
+	if strings.Contains(canonical, "[docs]:") {
+		t.Errorf("reference definition kept:\n%s", canonical)
+	}
+
+	stubReadmes(t, map[string]string{"octo/canonical": canonical})
+	again := getReadme(t, "owner=octo&repo=canonical&canonical=true", nil).Body.String()
+	if again != canonical {
+		t.Errorf("canonicalizing twice changed the output:\nfirst:\n%s\nsecond:\n%s", canonical, again)
+	}
+}
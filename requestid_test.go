@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Helper function to capture log output for one test
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := log.Writer()
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(previous) })
+	return &buf
+}
+
+func TestInboundRequestIDIsEchoed(t *testing.T) {
+	var seen string
+	handler := withRequestID(func(w http.ResponseWriter, r *http.Request) {
+		seen = requestIDFromContext(r.Context())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/readme", nil)
+	req.Header.Set("X-Request-ID", "abc-123")
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if got := rec.Header().Get("X-Request-ID"); got != "abc-123" {
+		t.Errorf("response X-Request-ID = %q, want abc-123", got)
+	}
+	if seen != "abc-123" {
+		t.Errorf("context request ID = %q, want abc-123", seen)
+	}
+}
+
+func TestRequestIDIsGeneratedWhenAbsentOrInvalid(t *testing.T) {
+	handler := withRequestID(func(http.ResponseWriter, *http.Request) {})
+
+	for _, inbound := range []string{"", "has space", strings.Repeat("x", maxRequestIDLength+1)} {
+		req := httptest.NewRequest(http.MethodGet, "/readme", nil)
+		if inbound != "" {
+			req.Header.Set("X-Request-ID", inbound)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+
+		got := rec.Header().Get("X-Request-ID")
+		if got == "" || got == inbound || len(got) != 32 {
+			t.Errorf("inbound %q: generated X-Request-ID = %q", inbound, got)
+		}
+	}
+}
+
+func TestParsingLogsCarryRequestID(t *testing.T) {
+	buf := captureLog(t)
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-42")
+
+	// An oversized inline SVG is dropped with a log line
+	svg := `<svg xmlns="http://www.w3.org/2000/svg"><title>` + strings.Repeat("x", 20<<10) + `</title></svg>`
+	parseHTMLToElements(ctx, svg)
+
+	if !strings.Contains(buf.String(), "[req-42] ") {
+		t.Errorf("log output %q lacks the request ID", buf.String())
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Report whether a URL is relative to the repository
+func isRelativeURL(raw string) bool {
+	if raw == "" || strings.HasPrefix(raw, "#") || strings.HasPrefix(raw, "//") {
+		return false
+	}
+	u, err := url.Parse(raw)
+	return err == nil && u.Scheme == "" && u.Host == ""
+}
+
+// Helper function to clean a repository-relative path
+func repoPath(raw string) string {
+	return strings.TrimPrefix(path.Clean("/"+raw), "/")
+}
+
+// Resolve a repository-relative image path to its raw file URL
+func rawFileURL(owner, repo, ref, raw string) string {
+	if !isRelativeURL(raw) {
+		return raw
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+	return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/%s", owner, repo, ref, repoPath(raw))
+}
+
+// Resolve a repository-relative link to its page on GitHub
+func blobFileURL(owner, repo, ref, raw string) string {
+	if !isRelativeURL(raw) {
+		return raw
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+	return fmt.Sprintf("https://github.com/%s/%s/blob/%s/%s", owner, repo, ref, repoPath(raw))
+}
+
+// Rewrite relative image sources and link targets to absolute GitHub URLs.
+// Paths resolve against the directory of the document, as GitHub does.
+func rewriteRelativeURLs(elements []Element, owner, repo, ref, docPath string) {
+	resolve := func(raw string) string {
+		if strings.HasPrefix(raw, "/") {
+			// Root-relative paths ignore the document directory
+			return raw
+		}
+		return path.Join(path.Dir(docPath), raw)
+	}
+
+	for i := range elements {
+		el := &elements[i]
+		switch el.Type {
+		case "image":
+			if isRelativeURL(el.Attributes.Src) {
+				el.Attributes.Src = rawFileURL(owner, repo, ref, resolve(el.Attributes.Src))
+			}
+		case "link":
+			if isRelativeURL(el.Attributes.Href) {
+				el.Attributes.Href = blobFileURL(owner, repo, ref, resolve(el.Attributes.Href))
+			}
+		}
+		rewriteRelativeURLs(el.Children, owner, repo, ref, docPath)
+	}
+}
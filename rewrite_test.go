@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestRelativeURLsAreRewritten(t *testing.T) {
+	source := "![arch](docs/arch.png)\n\n![badge](https://img.shields.io/badge.svg)\n\n" +
+		"[guide](docs/GUIDE.md) [site](https://example.com) [usage](#usage) [root](/LICENSE)\n"
+	elements := parseTestMarkdown(t, source)
+	rewriteRelativeURLs(elements, "octo", "demo", "main", "")
+
+	images := findElements(elements, "image")
+	wantImages := []string{
+		"https://raw.githubusercontent.com/octo/demo/main/docs/arch.png",
+		"https://img.shields.io/badge.svg",
+	}
+	for i, want := range wantImages {
+		if i >= len(images) || images[i].Attributes.Src != want {
+			t.Errorf("image %d = %+v, want %s", i, images, want)
+		}
+	}
+
+	links := findElements(elements, "link")
+	wantLinks := []string{
+		"https://github.com/octo/demo/blob/main/docs/GUIDE.md",
+		"https://example.com",
+		"#usage",
+		"https://github.com/octo/demo/blob/main/LICENSE",
+	}
+	for i, want := range wantLinks {
+		if i >= len(links) || links[i].Attributes.Href != want {
+			t.Errorf("link %d = %+v, want %s", i, links, want)
+		}
+	}
+}
+
+func TestRelativeURLsResolveAgainstTheDocument(t *testing.T) {
+	elements := parseTestMarkdown(t, "![shot](../images/shot.png) [next](next.md)\n")
+	rewriteRelativeURLs(elements, "octo", "demo", "", "docs/guide/README.md")
+
+	if image := findElement(elements, "image"); image == nil || image.Attributes.Src != "https://raw.githubusercontent.com/octo/demo/HEAD/docs/images/shot.png" {
+		t.Errorf("image = %+v", image)
+	}
+	if link := findElement(elements, "link"); link == nil || link.Attributes.Href != "https://github.com/octo/demo/blob/HEAD/docs/guide/next.md" {
+		t.Errorf("link = %+v", link)
+	}
+}
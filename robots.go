@@ -0,0 +1,36 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// An HTML comment asking for the document not to be indexed
+var noIndexCommentPattern = regexp.MustCompile(`(?i)<!--\s*noindex\s*-->`)
+
+// A robots directive inside leading front matter
+var robotsFrontMatterPattern = regexp.MustCompile(`(?im)^robots\s*:\s*["']?([^"'\n]*)`)
+
+// Report whether the author asked for the document not to be indexed
+func detectNoIndex(markdownContent string) bool {
+	if noIndexCommentPattern.MatchString(markdownContent) {
+		return true
+	}
+
+	// Front matter must open on the first line
+	rest, ok := strings.CutPrefix(strings.TrimPrefix(markdownContent, "\uFEFF"), "---\n")
+	if !ok {
+		return false
+	}
+	frontMatter, _, ok := strings.Cut(rest, "\n---")
+	if !ok {
+		return false
+	}
+
+	for _, match := range robotsFrontMatterPattern.FindAllStringSubmatch(frontMatter, -1) {
+		if strings.Contains(strings.ToLower(match[1]), "noindex") {
+			return true
+		}
+	}
+	return false
+}
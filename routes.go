@@ -0,0 +1,159 @@
+package main
+
+import "net/http"
+
+// An HTTP endpoint, used both to register handlers and to describe the API
+type route struct {
+	Path        string
+	Method      string
+	Summary     string
+	Handler     http.HandlerFunc
+	Params      []routeParam
+	RequestBody any
+	Response    any
+}
+
+// A query parameter accepted by a route
+type routeParam struct {
+	Name        string
+	Type        string
+	Description string
+	Required    bool
+}
+
+// Query parameters shared by endpoints that serve a parsed document
+func documentParams() []routeParam {
+	return append([]routeParam{
+		{Name: "owner", Type: "string", Description: "Repository owner", Required: true},
+		{Name: "repo", Type: "string", Description: "Repository name", Required: true},
+	}, documentOptionParams()...)
+}
+
+// Query parameters controlling how documents are parsed and returned
+func documentOptionParams() []routeParam {
+	params := []routeParam{
+		{Name: "path", Type: "string", Description: "File to render instead of the README"},
+		{Name: "ref", Type: "string", Description: "Branch, tag or commit to fetch from, defaults to the repository's default branch"},
+		{Name: "release", Type: "string", Description: "Release tag to fetch the README at, adding release details to the metadata"},
+		{Name: "compare", Type: "string", Description: "Return only README sections that changed between two refs, as base..head"},
+		{Name: "format", Type: "string", Description: "Response format: json, html, dot, markdown or text"},
+		{Name: "canonical", Type: "boolean", Description: "Return normalized markdown suitable for storage and diffing"},
+		{Name: "envelope", Type: "boolean", Description: "Wrap the document with response metadata"},
+		{Name: "casing", Type: "string", Description: "JSON key casing: snake or camel"},
+		{Name: "offset", Type: "integer", Description: "First top-level element to return"},
+		{Name: "limit", Type: "integer", Description: "Maximum number of top-level elements to return"},
+		{Name: "tocdepth", Type: "integer", Description: "Deepest heading level in the table of contents"},
+		{Name: "tocstyle", Type: "string", Description: "Table of contents layout: nested or flat"},
+		{Name: "truncate", Type: "integer", Description: "Cap text and code content at this many characters"},
+		{Name: "hardbreaks", Type: "boolean", Description: "Treat every newline as a line break, true by default"},
+		{Name: "tables", Type: "boolean", Description: "Parse GFM tables, true by default"},
+		{Name: "footnotes", Type: "boolean", Description: "Parse footnotes, false by default"},
+		{Name: "features", Type: "string", Description: "Comma-separated list of boolean features to enable"},
+		{Name: "debugraw", Type: "boolean", Description: "Include raw upstream responses, requires DEBUG=true"},
+	}
+
+	// Every named feature is also accepted as its own boolean parameter
+	for _, name := range featureNames() {
+		description := "Enable the " + name + " feature"
+		switch name {
+		case "sanitize":
+			description = "Strip raw HTML the sanitizer policy does not allow, on unless set to false"
+		case "emoji":
+			description = "Replace GitHub emoji shortcodes such as :rocket:, on unless set to false"
+		}
+		params = append(params, routeParam{Name: name, Type: "boolean", Description: description})
+	}
+
+	return params
+}
+
+// Query parameters of batch requests, which always return JSON documents
+func batchOptionParams() []routeParam {
+	var params []routeParam
+	for _, param := range documentOptionParams() {
+		switch param.Name {
+		case "format", "canonical", "envelope":
+			continue
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// All endpoints served by the application
+func apiRoutes() []route {
+	return []route{
+		{
+			Path:    "/",
+			Method:  http.MethodGet,
+			Summary: "Landing page with usage examples",
+			Handler: handleLandingRequest,
+		},
+		{
+			Path:    "/favicon.ico",
+			Method:  http.MethodGet,
+			Summary: "Empty favicon",
+			Handler: handleFaviconRequest,
+		},
+		{
+			Path:     "/readme",
+			Method:   http.MethodGet,
+			Summary:  "Parse a repository README into structured elements",
+			Handler:  handleReadmeRequest,
+			Params:   documentParams(),
+			Response: MarkdownDocument{},
+		},
+		{
+			Path:        "/readme/batch",
+			Method:      http.MethodPost,
+			Summary:     "Parse the READMEs of several repositories",
+			Handler:     handleBatchRequest,
+			Params:      batchOptionParams(),
+			RequestBody: []BatchItem{},
+			Response:    []BatchResult{},
+		},
+		{
+			Path:    "/wiki",
+			Method:  http.MethodGet,
+			Summary: "Parse a repository wiki page into structured elements",
+			Handler: handleWikiRequest,
+			Params: append(documentParams(),
+				routeParam{Name: "page", Type: "string", Description: "Wiki page name, defaults to Home"}),
+			Response: MarkdownDocument{},
+		},
+		{
+			Path:    "/license",
+			Method:  http.MethodGet,
+			Summary: "Fetch the decoded license file and its SPDX identifier",
+			Handler: handleLicenseRequest,
+			Params: []routeParam{
+				{Name: "owner", Type: "string", Description: "Repository owner", Required: true},
+				{Name: "repo", Type: "string", Description: "Repository name", Required: true},
+			},
+			Response: LicenseDocument{},
+		},
+		{
+			Path:     "/webhook",
+			Method:   http.MethodPost,
+			Summary:  "Invalidate cached documents for a repository from a signed GitHub webhook",
+			Handler:  handleWebhookRequest,
+			Response: map[string]int{},
+		},
+		{
+			Path:    "/healthz",
+			Method:  http.MethodGet,
+			Summary: "Liveness probe, checking GitHub connectivity with deep=true",
+			Handler: handleHealthRequest,
+			Params: []routeParam{
+				{Name: "deep", Type: "boolean", Description: "Also verify the GitHub token with an authenticated call"},
+			},
+			Response: HealthStatus{},
+		},
+		{
+			Path:    "/openapi.json",
+			Method:  http.MethodGet,
+			Summary: "OpenAPI description of this API",
+			Handler: handleOpenAPIRequest,
+		},
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// SanitizePolicy Tags, attributes and URL schemes allowed through the sanitizer
+type SanitizePolicy struct {
+	AllowedTags []string `json:"allowedTags"`
+	// Allowed attributes per tag, "*" applies to every tag
+	AllowedAttributes map[string][]string `json:"allowedAttributes"`
+	AllowedSchemes    []string            `json:"allowedSchemes"`
+}
+
+// Tags removed together with their content when not allowed
+var dropContentTags = []string{"script", "style", "iframe", "object", "embed", "noscript", "template", "frame", "frameset"}
+
+// Attributes holding URLs that must use an allowed scheme
+var urlAttributes = []string{"href", "src", "cite", "action", "formaction", "poster"}
+
+// Active sanitizer policy, replaced at startup from SANITIZE_POLICY_FILE
+var sanitizePolicy = defaultSanitizePolicy()
+
+// Safe default policy covering what markdown renders to
+func defaultSanitizePolicy() SanitizePolicy {
+	return SanitizePolicy{
+		AllowedTags: []string{
+			"h1", "h2", "h3", "h4", "h5", "h6", "p", "br", "hr",
+			"a", "img", "picture", "source", "code", "pre", "kbd", "samp",
+			"strong", "b", "em", "i", "del", "s", "ins", "sup", "sub", "mark",
+			"ul", "ol", "li", "dl", "dt", "dd", "blockquote",
+			"table", "thead", "tbody", "tfoot", "tr", "th", "td",
+			"details", "summary", "div", "span", "input", "time",
+		},
+		AllowedAttributes: map[string][]string{
+			"*":      {"id", "class", "title", "align", "dir", "lang", "data-*"},
+			"a":      {"href", "name"},
+			"img":    {"src", "alt", "width", "height", "loading", "decoding"},
+			"source": {"srcset", "media", "type"},
+			"input":  {"type", "checked", "disabled"},
+			"ol":     {"start"},
+			"td":     {"colspan", "rowspan"},
+			"th":     {"colspan", "rowspan"},
+			"time":   {"datetime"},
+		},
+		AllowedSchemes: []string{"http", "https", "mailto"},
+	}
+}
+
+// Load a sanitizer policy from a JSON file
+func loadSanitizePolicy(path string) (SanitizePolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SanitizePolicy{}, fmt.Errorf("reading policy: %w", err)
+	}
+
+	var policy SanitizePolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return SanitizePolicy{}, fmt.Errorf("parsing policy: %w", err)
+	}
+	if len(policy.AllowedTags) == 0 {
+		return SanitizePolicy{}, fmt.Errorf("policy allows no tags")
+	}
+
+	return policy, nil
+}
+
+func (p SanitizePolicy) allowsTag(tag string) bool {
+	return slices.Contains(p.AllowedTags, tag)
+}
+
+func (p SanitizePolicy) allowsAttr(tag, attr string) bool {
+	return matchesAttr(p.AllowedAttributes["*"], attr) || matchesAttr(p.AllowedAttributes[tag], attr)
+}
+
+// Helper function to match an attribute against allowed names, where a
+// trailing "*" allows a prefix such as "data-*"
+func matchesAttr(names []string, attr string) bool {
+	for _, name := range names {
+		if prefix, ok := strings.CutSuffix(name, "*"); ok && prefix != "" {
+			if strings.HasPrefix(attr, prefix) && len(attr) > len(prefix) {
+				return true
+			}
+		} else if name == attr {
+			return true
+		}
+	}
+	return false
+}
+
+// Helper function to check a URL attribute against the allowed schemes
+func (p SanitizePolicy) allowsURL(raw string) bool {
+	// Browsers ignore whitespace and control characters inside a scheme,
+	// e.g. "java&#9;script:"
+	u, err := url.Parse(strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, raw))
+	if err != nil {
+		return false
+	}
+	// Relative URLs and fragments carry no scheme
+	return u.Scheme == "" || slices.Contains(p.AllowedSchemes, strings.ToLower(u.Scheme))
+}
+
+// Remove disallowed tags and attributes from rendered HTML
+func sanitizeHTML(ctx context.Context, htmlContent string, policy SanitizePolicy) string {
+	nodes, err := html.ParseFragment(strings.NewReader(htmlContent), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		logf(ctx, "Error parsing HTML for sanitizing: %v", err)
+		return ""
+	}
+
+	// Collect the fragment under a root so top-level nodes are sanitized like any child
+	root := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	for _, n := range nodes {
+		root.AppendChild(n)
+	}
+	sanitizeChildren(root, policy)
+
+	var sb strings.Builder
+	for c := root.FirstChild; c != nil; c = c.NextSibling {
+		if err := html.Render(&sb, c); err != nil {
+			logf(ctx, "Error rendering sanitized HTML: %v", err)
+		}
+	}
+
+	return sb.String()
+}
+
+// Helper function to sanitize the children of a node in place
+func sanitizeChildren(n *html.Node, policy SanitizePolicy) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+
+		switch {
+		case c.Type != html.ElementNode:
+			// Text and comments are escaped on render
+
+		case c.Data == "svg":
+			// Inline SVG goes through its own allowlist
+			if _, ok := safeInlineSVG(c, policy); !ok {
+				n.RemoveChild(c)
+			}
+
+		case policy.allowsTag(c.Data):
+			c.Attr = slices.DeleteFunc(c.Attr, func(a html.Attribute) bool {
+				key := strings.ToLower(a.Key)
+				if !policy.allowsAttr(c.Data, key) {
+					return true
+				}
+				return slices.Contains(urlAttributes, key) && !policy.allowsURL(a.Val)
+			})
+			sanitizeChildren(c, policy)
+
+		case slices.Contains(dropContentTags, c.Data):
+			n.RemoveChild(c)
+
+		default:
+			// Unwrap unknown tags, keeping their sanitized content
+			sanitizeChildren(c, policy)
+			for gc := c.FirstChild; gc != nil; {
+				gcNext := gc.NextSibling
+				c.RemoveChild(gc)
+				n.InsertBefore(gc, c)
+				gc = gcNext
+			}
+			n.RemoveChild(c)
+		}
+
+		c = next
+	}
+}
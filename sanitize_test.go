@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeRemovesScriptsAndEventHandlers(t *testing.T) {
+	markdownContent := "Hello\n\n<script>alert(1)</script>\n\n<img src=\"x.png\" onerror=\"alert(2)\" alt=\"x\">\n"
+
+	sanitized := renderContentHTML(context.Background(), markdownContent, true, testOptions(t, "").Markdown)
+	if strings.Contains(sanitized, "script") || strings.Contains(sanitized, "alert") || strings.Contains(sanitized, "onerror") {
+		t.Errorf("sanitized HTML still carries script: %q", sanitized)
+	}
+
+	doc := renderDocument(context.Background(), markdownContent, DocumentMetadata{}, testOptions(t, ""))
+	for _, el := range findElements(doc.Content, "text") {
+		if strings.Contains(el.Content, "alert") {
+			t.Errorf("script content reached the elements: %+v", el)
+		}
+	}
+	image := findElement(doc.Content, "image")
+	if image == nil || image.Attributes.Src != "x.png" {
+		t.Errorf("image was not kept: %+v", doc.Content)
+	}
+}
+
+func TestSanitizeCanBeDisabled(t *testing.T) {
+	sanitized := renderContentHTML(context.Background(), "<iframe src=\"https://example.com\"></iframe>\n", false, testOptions(t, "").Markdown)
+	if !strings.Contains(sanitized, "<iframe") {
+		t.Errorf("sanitize=false still removed the iframe: %q", sanitized)
+	}
+}
+
+func TestSanitizeDropsUnsafeURLs(t *testing.T) {
+	sanitized := sanitizeHTML(context.Background(), `<a href="javascript:alert(1)">x</a><a href="https://example.com">y</a>`, defaultSanitizePolicy())
+	if strings.Contains(sanitized, "javascript") {
+		t.Errorf("javascript URL survived: %q", sanitized)
+	}
+	if !strings.Contains(sanitized, `href="https://example.com"`) {
+		t.Errorf("https URL was removed: %q", sanitized)
+	}
+}
+
+func TestDefaultPolicyKeepsDataAttributes(t *testing.T) {
+	doc := renderDocument(context.Background(), "<div data-component=\"chart\" onclick=\"x()\">\n\nBody\n\n</div>\n", DocumentMetadata{}, testOptions(t, ""))
+
+	container := findElement(doc.Content, "container")
+	if container == nil || container.Attributes.Data["component"] != "chart" {
+		t.Errorf("default policy stripped data-*: %+v", doc.Content)
+	}
+}
+
+func TestDataPrefixDoesNotMatchBareName(t *testing.T) {
+	policy := defaultSanitizePolicy()
+	if !policy.allowsAttr("div", "data-x") {
+		t.Error("data-x was not allowed")
+	}
+	if policy.allowsAttr("div", "data-") || policy.allowsAttr("div", "database") {
+		t.Error("data-* prefix matched a non data attribute")
+	}
+}
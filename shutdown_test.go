@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Helper function to find a free local address for a test server
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+// Helper function to wait until a server accepts connections
+func waitForServer(t *testing.T, addr string) {
+	t.Helper()
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("server on %s did not start", addr)
+}
+
+func TestShutdownFinishesActiveRequests(t *testing.T) {
+	captureLog(t)
+	started := make(chan struct{})
+	addr := freeAddr(t)
+	srv := &http.Server{Addr: addr, Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		io.WriteString(w, "done")
+	})}
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	serverDone := make(chan error, 1)
+	go func() { serverDone <- runServer(ctx, srv, 5*time.Second) }()
+	waitForServer(t, addr)
+
+	type result struct {
+		body string
+		err  error
+	}
+	response := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr + "/slow")
+		if err != nil {
+			response <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		response <- result{string(body), err}
+	}()
+
+	<-started
+	stop()
+
+	got := <-response
+	if got.err != nil || got.body != "done" {
+		t.Errorf("in-flight request got %q, %v, want it to complete", got.body, got.err)
+	}
+	if err := <-serverDone; err != nil {
+		t.Errorf("runServer: %v", err)
+	}
+	if _, err := http.Get("http://" + addr + "/slow"); err == nil {
+		t.Error("server still accepts requests after shutdown")
+	}
+}
+
+func TestShutdownTimeoutFromEnv(t *testing.T) {
+	t.Setenv("SHUTDOWN_TIMEOUT", "")
+	if timeout, err := shutdownTimeoutFromEnv(); err != nil || timeout != defaultShutdownTimeout {
+		t.Errorf("unset: got %v, %v", timeout, err)
+	}
+	t.Setenv("SHUTDOWN_TIMEOUT", "3s")
+	if timeout, err := shutdownTimeoutFromEnv(); err != nil || timeout != 3*time.Second {
+		t.Errorf("3s: got %v, %v", timeout, err)
+	}
+	t.Setenv("SHUTDOWN_TIMEOUT", "soon")
+	if _, err := shutdownTimeoutFromEnv(); err == nil {
+		t.Error("invalid timeout was accepted")
+	}
+}
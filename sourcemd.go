@@ -0,0 +1,166 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+// How many markdown blocks to look ahead when matching an element
+const sourceMatchLookahead = 3
+
+// Attach the originating markdown to top-level elements, best-effort.
+// Top-level markdown blocks are located in the source by their content and
+// matched to elements in order; elements without a confident match are left empty.
+func applySourceMarkdown(elements []Element, markdownContent string, md markdownOptions) {
+	matchSourceBlocks(elements, markdownBlockSources(markdownContent, md), func(el *Element, block sourceBlock) {
+		el.SourceMarkdown = block.Source
+	})
+}
+
+// Record the 1-based source line each top-level element starts on, matched
+// the same way as applySourceMarkdown
+func applySourcePositions(elements []Element, markdownContent string, md markdownOptions) {
+	matchSourceBlocks(elements, markdownBlockSources(markdownContent, md), func(el *Element, block sourceBlock) {
+		el.Line = strings.Count(markdownContent[:block.Start], "\n") + 1
+	})
+}
+
+// Helper function to pair elements with the blocks holding their text, in order
+func matchSourceBlocks(elements []Element, blocks []sourceBlock, apply func(*Element, sourceBlock)) {
+	cursor := 0
+	for i := range elements {
+		needle := firstElementText(elements[i])
+		if needle == "" {
+			continue
+		}
+
+		for j := cursor; j < len(blocks) && j < cursor+sourceMatchLookahead; j++ {
+			if blocks[j].Source != "" && strings.Contains(blocks[j].Source, needle) {
+				apply(&elements[i], blocks[j])
+				cursor = j + 1
+				break
+			}
+		}
+	}
+}
+
+// Source of a top-level markdown block and its byte offset in the document
+type sourceBlock struct {
+	Source string
+	Start  int
+}
+
+// Split markdown into the source of each top-level AST block
+func markdownBlockSources(markdownContent string, md markdownOptions) []sourceBlock {
+	root := parser.NewWithExtensions(md.extensions()).Parse([]byte(markdownContent))
+	children := root.GetChildren()
+
+	// Locate each block by the start of the line holding its content
+	starts := make([]int, len(children))
+	pos := 0
+	for i, child := range children {
+		starts[i] = -1
+
+		lineStart, ok := -1, false
+		if block, isCode := child.(*ast.CodeBlock); isCode && block.IsFenced {
+			// Fenced code starts at its fence rather than its first line of code
+			for _, fence := range []string{"```", "~~~"} {
+				if start, found := findBlockLine(markdownContent, pos, fence); found && (!ok || start < lineStart) {
+					lineStart, ok = start, true
+				}
+			}
+		} else if needle := blockNeedle(child); needle != "" {
+			lineStart, ok = findBlockLine(markdownContent, pos, needle)
+		}
+		if !ok {
+			continue
+		}
+		starts[i] = lineStart
+		pos = lineStart + 1
+	}
+
+	// Each located block extends to the next located block
+	sources := make([]sourceBlock, len(children))
+	for i, start := range starts {
+		if start < 0 {
+			continue
+		}
+		end := len(markdownContent)
+		for _, next := range starts[i+1:] {
+			if next >= 0 {
+				end = next
+				break
+			}
+		}
+		sources[i] = sourceBlock{Source: strings.TrimSpace(markdownContent[start:end]), Start: start}
+	}
+
+	return sources
+}
+
+// Only markdown syntax may precede a block's text on its first line
+var blockPrefixPattern = regexp.MustCompile(`^[\s#>*+\-\d.)|\[\]!~` + "`" + `]*$`)
+
+// Helper function to find the start of the line where needle begins a block
+func findBlockLine(markdownContent string, pos int, needle string) (int, bool) {
+	for pos < len(markdownContent) {
+		idx := strings.Index(markdownContent[pos:], needle)
+		if idx < 0 {
+			return 0, false
+		}
+		idx += pos
+
+		lineStart := strings.LastIndex(markdownContent[:idx], "\n") + 1
+		if blockPrefixPattern.MatchString(markdownContent[lineStart:idx]) {
+			return lineStart, true
+		}
+		pos = idx + 1
+	}
+	return 0, false
+}
+
+// Helper function to get text identifying where a block starts in the source
+func blockNeedle(node ast.Node) string {
+	var needle string
+	ast.WalkFunc(node, func(n ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		if c := n.AsContainer(); c != nil && len(c.Content) > 0 {
+			needle = firstLine(string(c.Content))
+		} else if l := n.AsLeaf(); l != nil && len(l.Literal) > 0 {
+			needle = firstLine(string(l.Literal))
+		}
+		if needle != "" {
+			return ast.Terminate
+		}
+		return ast.GoToNext
+	})
+	return needle
+}
+
+// Helper function to get the first non-blank line of s
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// Helper function to get the first piece of text within an element
+func firstElementText(el Element) string {
+	if text := firstLine(el.Content); text != "" {
+		return text
+	}
+	for _, child := range el.Children {
+		if text := firstElementText(child); text != "" {
+			return text
+		}
+	}
+	return ""
+}
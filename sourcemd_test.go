@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPositionsReportSourceLines(t *testing.T) {
+	source := "# First\n\nSome text\nwrapped\n\n## Second\n\n- a\n- b\n"
+	doc := renderDocument(context.Background(), source, DocumentMetadata{}, testOptions(t, "positions=true"))
+
+	want := []struct {
+		elementType string
+		line        int
+	}{
+		{"heading", 1},
+		{"paragraph", 3},
+		{"heading", 6},
+		{"unordered_list", 8},
+	}
+	if len(doc.Content) != len(want) {
+		t.Fatalf("got %d elements, want %d: %+v", len(doc.Content), len(want), doc.Content)
+	}
+	for i, w := range want {
+		if el := doc.Content[i]; el.Type != w.elementType || el.Line != w.line {
+			t.Errorf("element %d = %s on line %d, want %s on line %d", i, el.Type, el.Line, w.elementType, w.line)
+		}
+	}
+}
+
+func TestPositionsCountFrontMatterLines(t *testing.T) {
+	source := "---\ntitle: Doc\n---\n\n# Heading\n"
+	doc := renderDocument(context.Background(), source, DocumentMetadata{}, testOptions(t, "positions=true"))
+
+	if len(doc.Content) == 0 || doc.Content[0].Line != 5 {
+		t.Errorf("content = %+v, want the heading on line 5", doc.Content)
+	}
+}
+
+func TestPositionsAreOffByDefault(t *testing.T) {
+	for _, el := range parseTestMarkdown(t, "# Heading\n\ntext\n") {
+		if el.Line != 0 {
+			t.Errorf("%s has line %d without positions=true", el.Type, el.Line)
+		}
+	}
+}
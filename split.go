@@ -0,0 +1,24 @@
+package main
+
+// Split top-level elements into sub-documents at each horizontal rule.
+// The rules themselves are dropped and empty parts are skipped.
+func splitOnHorizontalRules(elements []Element) [][]Element {
+	var parts [][]Element
+	var current []Element
+
+	for _, el := range elements {
+		if el.Type == "horizontal_rule" {
+			if len(current) > 0 {
+				parts = append(parts, current)
+			}
+			current = nil
+			continue
+		}
+		current = append(current, el)
+	}
+	if len(current) > 0 {
+		parts = append(parts, current)
+	}
+
+	return parts
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Largest inline SVG accepted, badges are a few kilobytes at most
+const maxInlineSVGBytes = 16 * 1024
+
+// SVG elements kept in inline SVG, enough for badges and simple icons.
+// Animation and <use> can rewrite or pull in attributes, so they are absent.
+var svgAllowedElements = map[string]bool{
+	"svg": true, "g": true, "path": true, "rect": true, "circle": true,
+	"ellipse": true, "line": true, "polyline": true, "polygon": true,
+	"text": true, "tspan": true, "title": true, "desc": true, "defs": true,
+	"lineargradient": true, "radialgradient": true, "stop": true,
+	"clippath": true, "mask": true, "a": true,
+}
+
+// Presentation and geometry attributes kept in inline SVG
+var svgAllowedAttributes = map[string]bool{
+	"xmlns": true, "version": true, "viewbox": true, "preserveaspectratio": true,
+	"id": true, "class": true, "role": true, "aria-label": true, "aria-hidden": true,
+	"width": true, "height": true, "x": true, "y": true, "x1": true, "y1": true,
+	"x2": true, "y2": true, "cx": true, "cy": true, "r": true, "rx": true, "ry": true,
+	"d": true, "points": true, "transform": true, "offset": true,
+	"fill": true, "fill-opacity": true, "fill-rule": true, "opacity": true,
+	"stroke": true, "stroke-width": true, "stroke-opacity": true,
+	"stroke-linecap": true, "stroke-linejoin": true, "stroke-dasharray": true,
+	"stop-color": true, "stop-opacity": true, "clip-path": true, "clip-rule": true,
+	"mask": true, "gradientunits": true, "gradienttransform": true,
+	"font-family": true, "font-size": true, "font-weight": true,
+	"text-anchor": true, "dominant-baseline": true, "textlength": true,
+	"lengthadjust": true, "shape-rendering": true,
+}
+
+// Sanitize an inline SVG in place and serialize it, rejecting oversized content
+func safeInlineSVG(n *html.Node, policy SanitizePolicy) (string, bool) {
+	sanitizeSVG(n, policy)
+
+	var sb strings.Builder
+	if err := html.Render(&sb, n); err != nil {
+		return "", false
+	}
+	if sb.Len() > maxInlineSVGBytes {
+		return "", false
+	}
+	return sb.String(), true
+}
+
+// Helper function to strip an SVG subtree down to allowed elements and
+// attributes. Links must use a scheme the policy allows.
+func sanitizeSVG(n *html.Node, policy SanitizePolicy) {
+	n.Attr = slices.DeleteFunc(n.Attr, func(a html.Attribute) bool {
+		key := strings.ToLower(a.Key)
+		if key == "href" && (a.Namespace == "" || a.Namespace == "xlink") {
+			return strings.ToLower(n.Data) != "a" || !policy.allowsURL(a.Val)
+		}
+		return a.Namespace != "" || !svgAllowedAttributes[key]
+	})
+
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		switch {
+		case c.Type == html.ElementNode && !svgAllowedElements[strings.ToLower(c.Data)]:
+			n.RemoveChild(c)
+		case c.Type == html.ElementNode:
+			sanitizeSVG(c, policy)
+		case c.Type == html.CommentNode:
+			n.RemoveChild(c)
+		}
+		c = next
+	}
+}
+
+// Helper function to describe an SVG from its aria-label or <title>
+func svgAltText(n *html.Node) string {
+	if label := getAttr(n, "aria-label"); label != "" {
+		return label
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == "title" {
+			return extractAllText(c)
+		}
+	}
+	return ""
+}
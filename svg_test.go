@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeHTMLStripsScriptableSVG(t *testing.T) {
+	md := defaultMarkdownOptions()
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"javascript link with tab", `<svg><a href="java&#9;script:alert(1)"><text>x</text></a></svg>`},
+		{"xlink javascript link", `<svg><a xlink:href="javascript:alert(1)"><text>x</text></a></svg>`},
+		{"animated href", `<svg><a><animate attributeName="href" values="javascript:alert(1)"/><text>x</text></a></svg>`},
+		{"set element", `<svg><a><set attributeName="href" to="javascript:alert(1)"/></a></svg>`},
+		{"use element", `<svg><use href="data:image/svg+xml,&lt;svg onload=alert(1)&gt;"/></svg>`},
+		{"event handler", `<svg onload="alert(1)"><rect width="1" height="1"/></svg>`},
+		{"script", `<svg><script>alert(1)</script></svg>`},
+		{"foreign object", `<svg><foreignObject><iframe src="javascript:alert(1)"></iframe></foreignObject></svg>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := strings.ToLower(renderContentHTML(context.Background(), tt.input+"\n", true, md))
+			for _, bad := range []string{"javascript", "animate", "<set", "<use", "onload", "<script", "foreignobject", "iframe"} {
+				if strings.Contains(out, bad) {
+					t.Errorf("sanitized HTML contains %q: %s", bad, out)
+				}
+			}
+		})
+	}
+}
+
+func TestInlineSVGBadgeSurvives(t *testing.T) {
+	badge := `<svg xmlns="http://www.w3.org/2000/svg" width="90" height="20" aria-label="build: passing">` +
+		`<title>build: passing</title><linearGradient id="s"><stop offset="0" stop-color="#bbb"/></linearGradient>` +
+		`<rect width="90" height="20" fill="#555"/><a href="https://example.com"><text x="5" y="14">build</text></a></svg>`
+
+	elements := parseTestMarkdown(t, "<p>"+badge+"</p>\n")
+	img := findElement(elements, "image")
+	if img == nil || img.Attributes.Format != "svg-inline" {
+		t.Fatalf("expected an inline SVG image, got %+v", elements)
+	}
+	if img.Attributes.Alt != "build: passing" {
+		t.Errorf("alt = %q, want %q", img.Attributes.Alt, "build: passing")
+	}
+	for _, want := range []string{"<rect", "linearGradient", `href="https://example.com"`, "<text"} {
+		if !strings.Contains(img.Attributes.SVG, want) {
+			t.Errorf("SVG lost %q: %s", want, img.Attributes.SVG)
+		}
+	}
+}
+
+func TestInlineSVGIsSanitizedInElements(t *testing.T) {
+	opts := testOptions(t, "sanitize=false")
+	doc := renderDocument(context.Background(), `<svg><a href="javascript:alert(1)"><text>x</text></a><animate attributeName="x"/></svg>`+"\n", DocumentMetadata{}, opts)
+
+	img := findElement(doc.Content, "image")
+	if img == nil {
+		t.Fatalf("expected an inline SVG image, got %+v", doc.Content)
+	}
+	if strings.Contains(img.Attributes.SVG, "javascript") || strings.Contains(img.Attributes.SVG, "animate") {
+		t.Errorf("unsafe SVG kept: %s", img.Attributes.SVG)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// TaskProgress Completion summary of a README's task lists
+type TaskProgress struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+}
+
+// Count the task list items of a parsed document, wherever they are nested.
+// Returns nil when the document has no task lists.
+func computeTaskProgress(elements []Element) *TaskProgress {
+	var progress TaskProgress
+	countTasks(elements, &progress)
+
+	if progress.Total == 0 {
+		return nil
+	}
+	return &progress
+}
+
+// Helper function to add the task list items of an element tree to progress
+func countTasks(elements []Element, progress *TaskProgress) {
+	for _, el := range elements {
+		if el.Type == "task_list_item" {
+			progress.Total++
+			if el.Attributes.Checked != nil && *el.Attributes.Checked {
+				progress.Completed++
+			}
+		}
+		countTasks(el.Children, progress)
+	}
+}
+
+// A task marker opening list item text. The markdown renderer leaves GFM
+// checkboxes as literal "[ ]" and "[x]" text.
+var taskMarkerPattern = regexp.MustCompile(`^\[([ xX])\](?:\s+|$)`)
+
+// Helper function to detect a checkbox input opening a list item, as in
+// HTML rendered by GitHub: <li><input type="checkbox" checked> done</li>
+func leadingCheckbox(li *html.Node) (checked bool, ok bool) {
+	n := li.FirstChild
+	for n != nil {
+		switch {
+		case n.Type == html.TextNode && strings.TrimSpace(n.Data) == "":
+			n = n.NextSibling
+		case n.Type == html.ElementNode && n.Data == "p":
+			// Loose lists wrap item content in a paragraph
+			n = n.FirstChild
+		case n.Type == html.ElementNode && n.Data == "input" && strings.EqualFold(getAttr(n, "type"), "checkbox"):
+			for _, a := range n.Attr {
+				if a.Key == "checked" {
+					return true, true
+				}
+			}
+			return false, true
+		default:
+			return false, false
+		}
+	}
+	return false, false
+}
+
+// Helper function to remove a literal task marker from the start of list
+// item content, reporting whether there was one and whether it was checked
+func stripTaskMarker(children []Element) (checked bool, ok bool) {
+	if len(children) == 0 {
+		return false, false
+	}
+	first := &children[0]
+	if first.Type == "paragraph" {
+		if len(first.Children) == 0 {
+			return false, false
+		}
+		first = &first.Children[0]
+	}
+	if first.Type != "text" {
+		return false, false
+	}
+
+	match := taskMarkerPattern.FindStringSubmatch(first.Content)
+	if match == nil {
+		return false, false
+	}
+	first.Content = first.Content[len(match[0]):]
+	return match[1] != " ", true
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// Helper function to compute task progress of a markdown document
+func testTaskProgress(t *testing.T, markdownContent string) *TaskProgress {
+	t.Helper()
+	return renderDocument(context.Background(), markdownContent, DocumentMetadata{}, testOptions(t, "")).Metadata.TaskProgress
+}
+
+func TestTaskProgressCountsItems(t *testing.T) {
+	progress := testTaskProgress(t, "- [x] done\n- [ ] todo\n- [X] also done\n  - [ ] nested\n")
+	if progress == nil || progress.Total != 4 || progress.Completed != 2 {
+		t.Errorf("progress = %+v, want 2 of 4", progress)
+	}
+}
+
+func TestTaskProgressCountsQuotedItems(t *testing.T) {
+	progress := testTaskProgress(t, "> - [x] quoted\n> - [ ] quoted todo\n")
+	if progress == nil || progress.Total != 2 || progress.Completed != 1 {
+		t.Errorf("progress = %+v, want 1 of 2", progress)
+	}
+}
+
+func TestTaskProgressIgnoresCode(t *testing.T) {
+	markdownContent := "Example:\n\n    - [x] indented code\n\n```\n- [ ] fenced code\n```\n\n- [ ] real\n"
+	progress := testTaskProgress(t, markdownContent)
+	if progress == nil || progress.Total != 1 || progress.Completed != 0 {
+		t.Errorf("progress = %+v, want 0 of 1", progress)
+	}
+}
+
+func TestTaskProgressCountsHTMLCheckboxes(t *testing.T) {
+	progress := testTaskProgress(t, "<ul>\n<li><input type=\"checkbox\" checked> done</li>\n<li><input type=\"checkbox\"> todo</li>\n</ul>\n")
+	if progress == nil || progress.Total != 2 || progress.Completed != 1 {
+		t.Errorf("progress = %+v, want 1 of 2", progress)
+	}
+}
+
+func TestTaskProgressIsNilWithoutTasks(t *testing.T) {
+	if progress := testTaskProgress(t, "- plain\n- list\n"); progress != nil {
+		t.Errorf("progress = %+v, want nil", progress)
+	}
+}
+
+func TestTaskListItemsCarryCheckedState(t *testing.T) {
+	list := findElement(parseTestMarkdown(t, "- [x] done\n- [ ] todo\n- plain\n"), "unordered_list")
+	if list == nil || len(list.Children) != 3 {
+		t.Fatalf("list = %+v, want three items", list)
+	}
+
+	tests := []struct {
+		wantType string
+		task     bool
+		checked  bool
+		text     string
+	}{
+		{"task_list_item", true, true, "done"},
+		{"task_list_item", true, false, "todo"},
+		{"list_item", false, false, "plain"},
+	}
+	for i, tt := range tests {
+		item := list.Children[i]
+		if item.Type != tt.wantType {
+			t.Errorf("item %d type = %q, want %q", i, item.Type, tt.wantType)
+		}
+		if got := item.Attributes.Checked; (got != nil) != tt.task || (got != nil && *got != tt.checked) {
+			t.Errorf("item %d checked = %v, want task %v checked %v", i, got, tt.task, tt.checked)
+		}
+		if got := RenderPlainText(item.Children); got != tt.text+"\n" {
+			t.Errorf("item %d text = %q, want %q", i, got, tt.text)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import "strings"
+
+// Set TextContent on every element with children to its flattened descendant text
+func applyTextContent(elements []Element) {
+	for i := range elements {
+		if len(elements[i].Children) > 0 {
+			applyTextContent(elements[i].Children)
+			elements[i].TextContent = flattenText(elements[i].Children)
+		}
+	}
+}
+
+// Helper function to join the text of an element tree with single spaces
+func flattenText(elements []Element) string {
+	var parts []string
+	var collect func([]Element)
+	collect = func(elements []Element) {
+		for _, el := range elements {
+			if len(el.Children) == 0 && el.Content != "" {
+				parts = append(parts, el.Content)
+			}
+			collect(el.Children)
+		}
+	}
+	collect(elements)
+
+	return strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Location timestamps such as LastUpdated are reported in
+var displayLocation = time.UTC
+
+// Read the display timezone from DISPLAY_TIMEZONE, defaulting to UTC
+func displayLocationFromEnv() (*time.Location, error) {
+	name := os.Getenv("DISPLAY_TIMEZONE")
+	if name == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DISPLAY_TIMEZONE %q: %w", name, err)
+	}
+	return loc, nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDisplayLocationFromEnv(t *testing.T) {
+	t.Setenv("DISPLAY_TIMEZONE", "")
+	if loc, err := displayLocationFromEnv(); err != nil || loc != time.UTC {
+		t.Errorf("unset: got %v, %v, want UTC", loc, err)
+	}
+
+	t.Setenv("DISPLAY_TIMEZONE", "Not/AZone")
+	if _, err := displayLocationFromEnv(); err == nil {
+		t.Error("invalid zone was accepted")
+	}
+}
+
+func TestLastUpdatedIsInDisplayLocation(t *testing.T) {
+	t.Setenv("DISPLAY_TIMEZONE", "America/New_York")
+	loc, err := displayLocationFromEnv()
+	if err != nil {
+		t.Fatalf("displayLocationFromEnv: %v", err)
+	}
+	previous := displayLocation
+	displayLocation = loc
+	t.Cleanup(func() { displayLocation = previous })
+
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"demo","updated_at":"2024-01-15T12:00:00Z"}`)
+	})
+	metadata, err := getRepositoryMetadata(context.Background(), "octo", "demo")
+	if err != nil {
+		t.Fatalf("getRepositoryMetadata: %v", err)
+	}
+	if got := metadata.LastUpdated.Location().String(); got != "America/New_York" {
+		t.Errorf("location = %q, want America/New_York", got)
+	}
+	if got := metadata.LastUpdated.Format(time.RFC3339); got != "2024-01-15T07:00:00-05:00" {
+		t.Errorf("LastUpdated = %s", got)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import "strconv"
+
+// TOCEntry Table of contents entry built from a heading
+type TOCEntry struct {
+	Level    int        `json:"level"`
+	Text     string     `json:"text"`
+	ID       string     `json:"id"`
+	Children []TOCEntry `json:"children,omitempty"`
+}
+
+// Table of contents layouts
+const (
+	tocStyleFlat   = "flat"
+	tocStyleNested = "nested"
+)
+
+// Build a table of contents from headings at or above maxDepth
+func buildTableOfContents(elements []Element, maxDepth int, style string) []TOCEntry {
+	var entries []TOCEntry
+
+	var collect func([]Element)
+	collect = func(elements []Element) {
+		for _, el := range elements {
+			if el.Type == "heading" {
+				level, err := strconv.Atoi(el.Attributes.Level)
+				if err == nil && level <= maxDepth {
+					entries = append(entries, TOCEntry{
+						Level: level,
+						Text:  el.Content,
+						ID:    el.Attributes.ID,
+					})
+				}
+			}
+			collect(el.Children)
+		}
+	}
+	collect(elements)
+
+	if style == tocStyleNested {
+		return nestTOCEntries(entries)
+	}
+	return entries
+}
+
+// Helper function to nest flat entries under the closest shallower heading
+func nestTOCEntries(flat []TOCEntry) []TOCEntry {
+	var nest func(entries []TOCEntry, level int) ([]TOCEntry, []TOCEntry)
+	nest = func(entries []TOCEntry, level int) ([]TOCEntry, []TOCEntry) {
+		var nested []TOCEntry
+		for len(entries) > 0 && entries[0].Level > level {
+			entry := entries[0]
+			entry.Children, entries = nest(entries[1:], entry.Level)
+			nested = append(nested, entry)
+		}
+		return nested, entries
+	}
+
+	nested, _ := nest(flat, 0)
+	return nested
+}
@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+// Helper function to build the table of contents of a markdown document
+func testTableOfContents(t *testing.T, markdownContent, rawQuery string) []TOCEntry {
+	t.Helper()
+	opts := testOptions(t, rawQuery)
+	return applyDocumentOptions(parseTestMarkdownDoc(t, markdownContent, rawQuery), "octo", "demo", opts).TableOfContents
+}
+
+const tocTestMarkdown = "# Project\n\n## Install\n\n## Usage\n\n### Command line\n"
+
+func TestTableOfContentsFollowsHeadings(t *testing.T) {
+	toc := testTableOfContents(t, tocTestMarkdown, "")
+	want := []TOCEntry{
+		{Level: 1, Text: "Project", ID: "project"},
+		{Level: 2, Text: "Install", ID: "install"},
+		{Level: 2, Text: "Usage", ID: "usage"},
+		{Level: 3, Text: "Command line", ID: "command-line"},
+	}
+	if len(toc) != len(want) {
+		t.Fatalf("toc = %+v, want %d entries", toc, len(want))
+	}
+	for i := range want {
+		if toc[i].Level != want[i].Level || toc[i].Text != want[i].Text || toc[i].ID != want[i].ID {
+			t.Errorf("entry %d = %+v, want %+v", i, toc[i], want[i])
+		}
+	}
+}
+
+func TestTableOfContentsDepthAndNesting(t *testing.T) {
+	if toc := testTableOfContents(t, tocTestMarkdown, "tocdepth=2"); len(toc) != 3 {
+		t.Errorf("tocdepth=2: toc = %+v, want three entries", toc)
+	}
+
+	toc := testTableOfContents(t, tocTestMarkdown, "tocstyle=nested")
+	if len(toc) != 1 || len(toc[0].Children) != 2 {
+		t.Fatalf("nested toc = %+v, want one root with two sections", toc)
+	}
+	usage := toc[0].Children[1]
+	if usage.ID != "usage" || len(usage.Children) != 1 || usage.Children[0].ID != "command-line" {
+		t.Errorf("usage entry = %+v, want command-line nested under it", usage)
+	}
+}
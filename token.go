@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Context key for a GitHub token supplied with the incoming request
+type githubTokenKey struct{}
+
+// Attach the caller's GitHub token, if the request carries one
+func withRequestToken(ctx context.Context, r *http.Request) context.Context {
+	token := strings.TrimSpace(r.Header.Get("X-GitHub-Token"))
+	if token == "" {
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			token = strings.TrimSpace(bearer)
+		}
+	}
+	if token == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, githubTokenKey{}, token)
+}
+
+// Report whether the request supplied its own GitHub token
+func hasRequestToken(ctx context.Context) bool {
+	token, _ := ctx.Value(githubTokenKey{}).(string)
+	return token != ""
+}
+
+// Get the token for GitHub calls: the caller's own, else GITHUB_TOKEN
+func githubToken(ctx context.Context) string {
+	if token, ok := ctx.Value(githubTokenKey{}).(string); ok && token != "" {
+		return token
+	}
+	return os.Getenv("GITHUB_TOKEN")
+}
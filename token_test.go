@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRequestTokenReachesGitHub(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Header.Get("Authorization"))
+		mu.Unlock()
+		if strings.HasSuffix(r.URL.Path, "/readme") {
+			w.Write([]byte(`{"content":"IyBEZW1v"}`))
+			return
+		}
+		w.Write([]byte(`{"name":"demo"}`))
+	})
+	logs := captureLog(t)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   string
+	}{
+		{"bearer", http.Header{"Authorization": {"Bearer caller-bearer"}}, "token caller-bearer"},
+		{"custom header", http.Header{"X-Github-Token": {"caller-custom"}}, "token caller-custom"},
+		{"no header", nil, "token test-token"},
+	}
+	for _, tt := range tests {
+		seen = nil
+		if rec := getReadme(t, "owner=octo&repo=demo", tt.header); rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d", tt.name, rec.Code)
+		}
+		if len(seen) == 0 {
+			t.Fatalf("%s: no GitHub calls", tt.name)
+		}
+		for _, authorization := range seen {
+			if authorization != tt.want {
+				t.Errorf("%s: Authorization = %q, want %q", tt.name, authorization, tt.want)
+			}
+		}
+	}
+
+	if strings.Contains(logs.String(), "caller-") {
+		t.Errorf("a caller token was logged: %s", logs.String())
+	}
+}
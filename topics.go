@@ -0,0 +1,118 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Language of each fenced code block in the raw markdown
+var fenceLanguagePattern = regexp.MustCompile("(?m)^\\s*(?:```|~~~)\\s*([A-Za-z0-9_+#.-]+)")
+
+// Normalized topic names for common code fence languages
+var languageTopics = map[string]string{
+	"go":         "go",
+	"golang":     "go",
+	"py":         "python",
+	"python":     "python",
+	"js":         "javascript",
+	"javascript": "javascript",
+	"jsx":        "javascript",
+	"ts":         "typescript",
+	"typescript": "typescript",
+	"tsx":        "typescript",
+	"rs":         "rust",
+	"rust":       "rust",
+	"rb":         "ruby",
+	"ruby":       "ruby",
+	"java":       "java",
+	"kotlin":     "kotlin",
+	"swift":      "swift",
+	"c":          "c",
+	"cpp":        "cpp",
+	"c++":        "cpp",
+	"cs":         "csharp",
+	"csharp":     "csharp",
+	"php":        "php",
+	"dockerfile": "docker",
+	"docker":     "docker",
+	"hcl":        "terraform",
+	"terraform":  "terraform",
+	"sql":        "sql",
+}
+
+// Heading keywords that indicate a topic
+var headingTopics = map[string]string{
+	"kubernetes": "kubernetes",
+	"k8s":        "kubernetes",
+	"helm":       "kubernetes",
+	"docker":     "docker",
+	"terraform":  "terraform",
+	"aws":        "aws",
+	"gcp":        "gcp",
+	"azure":      "azure",
+	"graphql":    "graphql",
+	"grpc":       "grpc",
+	"react":      "react",
+	"vue":        "vue",
+	"django":     "django",
+	"flask":      "flask",
+	"rails":      "rails",
+	"cli":        "cli",
+	"api":        "api",
+}
+
+// DetectTopics Infer topics from headings, code block languages and repository metadata
+func DetectTopics(doc MarkdownDocument) []string {
+	found := make(map[string]bool)
+
+	// Code block languages
+	for _, match := range fenceLanguagePattern.FindAllStringSubmatch(doc.RawContent, -1) {
+		if topic, ok := languageTopics[strings.ToLower(match[1])]; ok {
+			found[topic] = true
+		}
+	}
+
+	// Heading keywords
+	var walk func([]Element)
+	walk = func(elements []Element) {
+		for _, el := range elements {
+			if el.Type == "heading" {
+				for _, word := range strings.FieldsFunc(strings.ToLower(el.Content), isTopicSeparator) {
+					if topic, ok := headingTopics[word]; ok {
+						found[topic] = true
+					}
+				}
+			}
+			walk(el.Children)
+		}
+	}
+	walk(doc.Content)
+
+	// Primary repository language reported by GitHub
+	if language := strings.ToLower(doc.Metadata.Language); language != "" {
+		if topic, ok := languageTopics[language]; ok {
+			found[topic] = true
+		} else {
+			found[language] = true
+		}
+	}
+
+	// Curated repository topics
+	for _, topic := range doc.Metadata.Topics {
+		found[strings.ToLower(topic)] = true
+	}
+
+	topics := make([]string, 0, len(found))
+	for topic := range found {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	return topics
+}
+
+// Helper function to split heading text into words
+func isTopicSeparator(r rune) bool {
+	return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '+' || r == '#')
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Cap the content of text and code elements at limit characters
+func applyTruncation(elements []Element, limit int) {
+	for i := range elements {
+		el := &elements[i]
+		switch el.Type {
+		case "text":
+			if content, cut := truncateWords(el.Content, limit); cut {
+				el.Content = content
+				el.Attributes.Truncated = true
+			}
+		case "code", "code_block":
+			if content, cut := truncateRunes(el.Content, limit); cut {
+				el.Content = content
+				el.Attributes.Truncated = true
+			}
+		}
+		applyTruncation(el.Children, limit)
+	}
+}
+
+// Helper function to cut code at exactly limit characters
+func truncateRunes(s string, limit int) (string, bool) {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s, false
+	}
+	return string(runes[:limit]) + "…", true
+}
+
+// Helper function to cut prose at the last word boundary within limit characters
+func truncateWords(s string, limit int) (string, bool) {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s, false
+	}
+
+	cut := limit
+	for cut > 0 && !unicode.IsSpace(runes[cut]) {
+		cut--
+	}
+	if cut == 0 {
+		// A single word longer than the limit
+		cut = limit
+	}
+
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace) + "…", true
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Reference-style links left as literal text when the reference is undefined
+var unresolvedReferencePattern = regexp.MustCompile(`\[([^\[\]]+)\]\[([^\[\]]*)\]`)
+
+// Collect authoring warnings for a parsed document
+func validateDocument(elements []Element) []string {
+	var warnings []string
+	seen := make(map[string]bool)
+
+	var walk func([]Element)
+	walk = func(elements []Element) {
+		for _, el := range elements {
+			// Code is expected to contain brackets verbatim
+			if el.Type == "code" || el.Type == "code_block" {
+				continue
+			}
+
+			if el.Type == "text" {
+				for _, match := range unresolvedReferencePattern.FindAllStringSubmatch(el.Content, -1) {
+					ref := match[2]
+					if ref == "" {
+						ref = match[1]
+					}
+					warning := fmt.Sprintf("unresolved reference link %q (undefined reference %q)", match[0], ref)
+					if !seen[warning] {
+						seen[warning] = true
+						warnings = append(warnings, warning)
+					}
+				}
+			}
+
+			walk(el.Children)
+		}
+	}
+	walk(elements)
+
+	return warnings
+}
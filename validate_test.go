@@ -0,0 +1,26 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDanglingReferenceLinkIsReported(t *testing.T) {
+	source := "See [the docs][docs] and [the guide][guide].\n\n[guide]: https://example.com/guide\n"
+	doc := parseTestMarkdownDoc(t, source, "validate=true")
+
+	warnings := validateDocument(doc.Content)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `"docs"`) {
+		t.Errorf("warnings = %q, want one for the docs reference", warnings)
+	}
+	if link := findElement(doc.Content, "link"); link == nil || link.Attributes.Href != "https://example.com/guide" {
+		t.Errorf("defined reference was not resolved: %+v", doc.Content)
+	}
+}
+
+func TestReferencesInCodeAreIgnored(t *testing.T) {
+	doc := parseTestMarkdownDoc(t, "Use `arr[i][j]` here\n\n```\nm[a][b] = 1\n```\n", "")
+	if warnings := validateDocument(doc.Content); len(warnings) != 0 {
+		t.Errorf("code produced warnings: %q", warnings)
+	}
+}
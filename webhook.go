@@ -0,0 +1,94 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Largest webhook payload accepted
+const maxWebhookPayloadBytes = 1 << 20
+
+// HTTP Handler for GitHub webhooks that invalidate cached documents
+func handleWebhookRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSONError(w, http.StatusMethodNotAllowed, errorCodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	secret := os.Getenv("GITHUB_WEBHOOK_SECRET")
+	if secret == "" {
+		writeJSONError(w, http.StatusServiceUnavailable, errorCodeUnavailable, "Webhooks are not configured")
+		return
+	}
+
+	// Read one byte past the limit so oversized payloads are rejected, not cut off
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookPayloadBytes+1))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, errorCodeBadRequest, "Failed to read payload")
+		return
+	}
+	if len(body) > maxWebhookPayloadBytes {
+		writeJSONError(w, http.StatusRequestEntityTooLarge, errorCodeTooLarge,
+			fmt.Sprintf("Payload is larger than the %d byte limit", maxWebhookPayloadBytes))
+		return
+	}
+
+	if !validWebhookSignature(secret, body, r.Header.Get("X-Hub-Signature-256")) {
+		writeJSONError(w, http.StatusUnauthorized, errorCodeUnauthorized, "Invalid signature")
+		return
+	}
+
+	var payload struct {
+		Repository struct {
+			Name  string `json:"name"`
+			Owner struct {
+				Login string `json:"login"`
+			} `json:"owner"`
+		} `json:"repository"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		writeJSONError(w, http.StatusBadRequest, errorCodeBadRequest, "Invalid payload")
+		return
+	}
+
+	owner := payload.Repository.Owner.Login
+	repo := payload.Repository.Name
+	if owner == "" || repo == "" {
+		// Events without a repository, such as some pings, have nothing to invalidate
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	removed := readmeCache.invalidateRepository(owner, repo)
+	logf(r.Context(), "Webhook %s invalidated %d cache entries for %s/%s",
+		r.Header.Get("X-GitHub-Event"), removed, owner, repo)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]int{"invalidated": removed}); err != nil {
+		logf(r.Context(), "Error encoding response: %v", err)
+	}
+}
+
+// Check a "sha256=<hex>" signature against the payload HMAC
+func validWebhookSignature(secret string, body []byte, signature string) bool {
+	digest, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	expected, err := hex.DecodeString(digest)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
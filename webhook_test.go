@@ -0,0 +1,94 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Helper function to sign a webhook payload the way GitHub does
+func signWebhook(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Helper function to deliver a webhook and decode a JSON error, if any
+func postWebhook(t *testing.T, method, body, signature string) (int, ErrorResponse) {
+	t.Helper()
+	req := httptest.NewRequest(method, "/webhook", strings.NewReader(body))
+	req.Header.Set("X-Hub-Signature-256", signature)
+	rec := httptest.NewRecorder()
+	handleWebhookRequest(rec, req)
+
+	var errResp ErrorResponse
+	if rec.Code >= 400 {
+		if got := rec.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", got)
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &errResp); err != nil {
+			t.Fatalf("decoding error body %q: %v", rec.Body.String(), err)
+		}
+	}
+	return rec.Code, errResp
+}
+
+func TestWebhookErrorsAreJSON(t *testing.T) {
+	t.Setenv("GITHUB_WEBHOOK_SECRET", "")
+	if status, errResp := postWebhook(t, http.MethodPost, "{}", ""); status != http.StatusServiceUnavailable || errResp.Code != errorCodeUnavailable {
+		t.Errorf("unconfigured: got %d %+v", status, errResp)
+	}
+
+	t.Setenv("GITHUB_WEBHOOK_SECRET", "secret")
+	tests := []struct {
+		name      string
+		method    string
+		body      string
+		signature string
+		status    int
+		code      string
+	}{
+		{"wrong method", http.MethodGet, "", "", http.StatusMethodNotAllowed, errorCodeMethodNotAllowed},
+		{"bad signature", http.MethodPost, "{}", signWebhook("other", "{}"), http.StatusUnauthorized, errorCodeUnauthorized},
+		{"invalid payload", http.MethodPost, "not json", signWebhook("secret", "not json"), http.StatusBadRequest, errorCodeBadRequest},
+	}
+	for _, tt := range tests {
+		status, errResp := postWebhook(t, tt.method, tt.body, tt.signature)
+		if status != tt.status || errResp.Code != tt.code || errResp.Error == "" {
+			t.Errorf("%s: got %d %+v, want %d %s", tt.name, status, errResp, tt.status, tt.code)
+		}
+	}
+}
+
+func TestWebhookInvalidatesRepository(t *testing.T) {
+	t.Setenv("GITHUB_WEBHOOK_SECRET", "secret")
+	stubReadmes(t, map[string]string{"octo/demo": "# Demo"})
+	getReadme(t, "owner=octo&repo=demo", nil)
+	key := readmeCacheKey("octo", "demo", testOptions(t, ""))
+	if _, ok := readmeCache.get(key); !ok {
+		t.Fatal("README was not cached")
+	}
+
+	body := `{"repository":{"name":"demo","owner":{"login":"octo"}}}`
+	if status, errResp := postWebhook(t, http.MethodPost, body, signWebhook("secret", body)); status != http.StatusOK {
+		t.Fatalf("got %d %+v, want 200", status, errResp)
+	}
+	if _, ok := readmeCache.get(key); ok {
+		t.Error("cached README survived the webhook")
+	}
+}
+
+func TestOversizedWebhookIsRejected(t *testing.T) {
+	t.Setenv("GITHUB_WEBHOOK_SECRET", "secret")
+	body := `{"padding":"` + strings.Repeat("x", maxWebhookPayloadBytes) + `"}`
+
+	status, errResp := postWebhook(t, http.MethodPost, body, signWebhook("secret", body))
+	if status != http.StatusRequestEntityTooLarge || errResp.Code != errorCodeTooLarge {
+		t.Errorf("got %d %+v, want 413", status, errResp)
+	}
+}
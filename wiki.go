@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// HTTP Handler for wiki page processing
+func handleWikiRequest(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers before validating, so browsers can read the errors
+	setCORSHeaders(w, r)
+
+	// Handle preflight requests
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	page := r.URL.Query().Get("page")
+	if page == "" {
+		page = "Home"
+	}
+
+	if strings.ContainsAny(page, "/\\") || strings.Contains(page, "..") {
+		writeJSONError(w, http.StatusBadRequest, errorCodeBadRequest, "Invalid wiki page name")
+		return
+	}
+
+	if !wikiAvailable() {
+		writeJSONError(w, http.StatusNotFound, errorCodeNotFound, "Wiki pages are only available on github.com")
+		return
+	}
+
+	serveDocument(w, r, "wiki page", func(ctx context.Context, owner, repo string, opts readmeOptions) (MarkdownDocument, error) {
+		return processWikiPage(ctx, owner, repo, page, opts)
+	})
+}
+
+// Process a wiki page through the README pipeline
+func processWikiPage(ctx context.Context, owner, repo, page string, opts readmeOptions) (MarkdownDocument, error) {
+	content, metadata, err := fetchWithMetadata(ctx, owner, repo, func(ctx context.Context) (string, error) {
+		content, err := getWikiContent(ctx, owner, repo, page)
+		if err != nil {
+			return "", fmt.Errorf("fetching wiki page: %w", err)
+		}
+		return content, nil
+	})
+	if err != nil {
+		return MarkdownDocument{}, err
+	}
+
+	// Wikis live in their own repository, the README branch does not apply
+	metadata.Ref = ""
+
+	doc := renderDocument(ctx, content, metadata, opts)
+	doc.pageURL = wikiPageURL(metadata.URL, page)
+	return doc, nil
+}
+
+// Helper function to tell whether wiki pages can be fetched from the
+// configured GitHub. GitHub Enterprise has no raw wiki endpoint, so the
+// github.com raw host would serve a different repository's wiki.
+func wikiAvailable() bool {
+	return sendsGitHubToken(githubRawBase)
+}
+
+// Fetch the raw markdown of a wiki page. Wikis live in the owner/repo.wiki
+// git repository, which github.com serves raw under /wiki/{owner}/{repo}.
+func getWikiContent(ctx context.Context, owner, repo, page string) (string, error) {
+	wikiURL := fmt.Sprintf("%s/wiki/%s/%s/%s.md",
+		githubRawBase, url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(page))
+
+	resp, body, err := doGitHubRequest(ctx, wikiURL, "")
+	if err != nil {
+		return "", err
+	}
+	recordDebugResponse(ctx, "wiki", body)
+	if err := checkGitHubResponse(resp, body); err != nil {
+		return "", fmt.Errorf("wiki page %q: %w", page, err)
+	}
+	if len(body) > maxContentBytes {
+		return "", errContentTooLarge
+	}
+
+	return string(body), nil
+}
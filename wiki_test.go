@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// Helper function to serve wiki pages from the test GitHub server
+func stubWiki(t *testing.T, pages map[string]string) {
+	t.Helper()
+	srv := stubGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		if page, ok := strings.CutPrefix(r.URL.Path, "/wiki/octo/demo/"); ok {
+			content, found := pages[strings.TrimSuffix(page, ".md")]
+			if !found {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprint(w, content)
+			return
+		}
+		fmt.Fprint(w, `{"name":"demo","html_url":"https://github.com/octo/demo","default_branch":"main"}`)
+	})
+
+	previous := githubRawBase
+	githubRawBase = srv.URL
+	t.Cleanup(func() { githubRawBase = previous })
+}
+
+// Helper function to call the wiki handler
+func getWiki(t *testing.T, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handleWikiRequest(rec, httptest.NewRequest(http.MethodGet, "/wiki?"+query, nil))
+	return rec
+}
+
+func TestWikiPageIsParsed(t *testing.T) {
+	stubWiki(t, map[string]string{"Setup": "# Setup\n\nSteps"})
+
+	doc, err := processWikiPage(context.Background(), "octo", "demo", "Setup", testOptions(t, ""))
+	if err != nil {
+		t.Fatalf("processWikiPage: %v", err)
+	}
+	if heading := findElement(doc.Content, "heading"); heading == nil || heading.Content != "Setup" {
+		t.Errorf("content = %+v", doc.Content)
+	}
+	if doc.Metadata.Ref != "" {
+		t.Errorf("wiki ref = %q, want none", doc.Metadata.Ref)
+	}
+	if doc.pageURL != "https://github.com/octo/demo/wiki/Setup" {
+		t.Errorf("page URL = %q", doc.pageURL)
+	}
+}
+
+func TestWikiPageNamesAreValidated(t *testing.T) {
+	for _, page := range []string{"../secrets", "a/b", `a\b`} {
+		rec := getWiki(t, "owner=octo&repo=demo&page="+url.QueryEscape(page))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("page %q: status %d, want 400", page, rec.Code)
+		}
+	}
+}
+
+func TestOversizedWikiPageIsRejected(t *testing.T) {
+	previous := maxContentBytes
+	maxContentBytes = 64
+	t.Cleanup(func() { maxContentBytes = previous })
+	stubWiki(t, map[string]string{"Home": strings.Repeat("x", 65)})
+
+	_, err := getWikiContent(context.Background(), "octo", "demo", "Home")
+	if !errors.Is(err, errContentTooLarge) {
+		t.Errorf("err = %v, want errContentTooLarge", err)
+	}
+}
+
+func TestWikiIsRejectedOnGitHubEnterprise(t *testing.T) {
+	stubWiki(t, map[string]string{"Home": "# Home"})
+	githubAPIBase = "https://ghe.example.com/api/v3"
+
+	rec := getWiki(t, "owner=octo&repo=demo")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status %d, want 404", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), errorCodeNotFound) {
+		t.Errorf("body = %s", rec.Body.String())
+	}
+}